@@ -0,0 +1,111 @@
+// Package wyrm tab completion for text input
+package wyrm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Completer returns the candidates for the last word of line, the text
+// before the cursor. Candidates not starting with the word are ignored.
+type Completer func(line string) []string
+
+// CompleteWords returns a Completer with a static list of candidates
+func CompleteWords(words ...string) Completer {
+	return func(string) []string {
+		return words
+	}
+}
+
+// CompleteFiles completes the last word of line as a file path.
+// Directories are completed with a trailing slash.
+func CompleteFiles(line string) []string {
+	word := lastWord(line)
+	dir, _ := filepath.Split(word)
+
+	entries, err := os.ReadDir(dirOrDot(dir))
+	if err != nil {
+		return nil
+	}
+
+	cands := []string{}
+	for _, e := range entries {
+		name := dir + e.Name()
+		if e.IsDir() {
+			name += string(filepath.Separator)
+		}
+		cands = append(cands, name)
+	}
+
+	return cands
+}
+
+// CompleteExecutables completes the first word of line with the executables
+// in $PATH and the following words as file paths
+func CompleteExecutables(line string) []string {
+	if strings.ContainsAny(line, " \t") {
+		return CompleteFiles(line)
+	}
+
+	seen := map[string]bool{}
+	cands := []string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dirOrDot(dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil || info.IsDir() || info.Mode()&0o111 == 0 || seen[e.Name()] {
+				continue
+			}
+			seen[e.Name()] = true
+			cands = append(cands, e.Name())
+		}
+	}
+
+	return cands
+}
+
+// completer adapts a Completer to the line editor
+type completer Completer
+
+// Do returns the suffixes completing the word before pos and the word length
+func (c completer) Do(line []rune, pos int) ([][]rune, int) {
+	if c == nil {
+		return nil, 0
+	}
+
+	s := string(line[:pos])
+	word := lastWord(s)
+
+	suffixes := []string{}
+	for _, cand := range c(s) {
+		if strings.HasPrefix(cand, word) && cand != word {
+			suffixes = append(suffixes, cand[len(word):])
+		}
+	}
+	sort.Strings(suffixes)
+
+	rs := [][]rune{}
+	for _, suffix := range suffixes {
+		rs = append(rs, []rune(suffix))
+	}
+
+	return rs, len([]rune(word))
+}
+
+// lastWord returns the text after the last space or tab in s
+func lastWord(s string) string {
+	return s[strings.LastIndexAny(s, " \t")+1:]
+}
+
+// dirOrDot returns dir, or "." if dir is empty
+func dirOrDot(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
@@ -0,0 +1,57 @@
+package wyrm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompleterDo(t *testing.T) {
+	c := completer(CompleteWords("apple", "apricot", "banana"))
+
+	cases := []struct {
+		line     string
+		expected []string
+		length   int
+	}{
+		{"", []string{"apple", "apricot", "banana"}, 0},
+		{"ap", []string{"ple", "ricot"}, 2},
+		{"eat b", []string{"anana"}, 1},
+		{"apple", []string{}, 5},
+		{"x", []string{}, 1},
+	}
+
+	for _, tc := range cases {
+		rs, n := c.Do([]rune(tc.line), len([]rune(tc.line)))
+		got := []string{}
+		for _, r := range rs {
+			got = append(got, string(r))
+		}
+		if !reflect.DeepEqual(got, tc.expected) || n != tc.length {
+			t.Errorf("Do(%q) = %q, %d, expected %q, %d", tc.line, got, n, tc.expected, tc.length)
+		}
+	}
+
+	if rs, n := completer(nil).Do([]rune("a"), 1); rs != nil || n != 0 {
+		t.Errorf("nil Do = %q, %d, expected nil, 0", rs, n)
+	}
+}
+
+func TestCompleteExecutables(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "tool"), nil, 0o755)
+	os.WriteFile(filepath.Join(dir, "data"), nil, 0o644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	t.Setenv("PATH", dir)
+
+	if got := CompleteExecutables("to"); !reflect.DeepEqual(got, []string{"tool"}) {
+		t.Errorf("CompleteExecutables(%q) = %q, expected %q", "to", got, []string{"tool"})
+	}
+
+	line := "ls " + dir + "/"
+	expected := []string{dir + "/data", dir + "/sub/", dir + "/tool"}
+	if got := CompleteExecutables(line); !reflect.DeepEqual(got, expected) {
+		t.Errorf("CompleteExecutables(%q) = %q, expected %q", line, got, expected)
+	}
+}
@@ -0,0 +1,105 @@
+// Package wyrm command context
+package wyrm
+
+import (
+	"context"
+	"sync"
+)
+
+// Context is passed to the context aware command functions.
+// The embedded context.Context is cancelled on SIGINT (Ctrl-C) or
+// when an input function is aborted with Esc.
+type Context struct {
+	context.Context
+	Wyrm    *Wyrm    // the running Wyrm
+	Command *Command // the invoked command
+	Key     rune     // the key that invoked the command
+	Path    []rune   // the keys from the root command to the command
+	Count   int      // the numeric prefix, 0 if none was typed
+}
+
+// running holds the cancel function of the running context aware command
+type running struct {
+	sync.Mutex
+	cancel context.CancelFunc
+}
+
+// cancelCommand cancels the context of the running command.
+// Returns false if no context aware command is running.
+func (w *Wyrm) cancelCommand() bool {
+	w.running.Lock()
+	defer w.running.Unlock()
+
+	if w.running.cancel == nil {
+		return false
+	}
+	w.running.cancel()
+	return true
+}
+
+// setCancelCommand sets the cancel function of the running command
+func (w *Wyrm) setCancelCommand(cancel context.CancelFunc) {
+	w.running.Lock()
+	w.running.cancel = cancel
+	w.running.Unlock()
+}
+
+// interrupt holds the channel of an input function waiting for SIGINT
+type interrupt struct {
+	sync.Mutex
+	ch chan struct{}
+}
+
+// watchInterrupt makes SIGINT signal the returned channel, instead of
+// cancelling the command or ending Run, until stop is called
+func (w *Wyrm) watchInterrupt() (ch <-chan struct{}, stop func()) {
+	c := make(chan struct{}, 1)
+
+	w.interrupt.Lock()
+	w.interrupt.ch = c
+	w.interrupt.Unlock()
+
+	return c, func() {
+		w.interrupt.Lock()
+		w.interrupt.ch = nil
+		w.interrupt.Unlock()
+	}
+}
+
+// interruptInput signals the input function waiting for SIGINT.
+// Returns false if no input function is waiting.
+func (w *Wyrm) interruptInput() bool {
+	w.interrupt.Lock()
+	defer w.interrupt.Unlock()
+
+	if w.interrupt.ch == nil {
+		return false
+	}
+	select {
+	case w.interrupt.ch <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// call calls fc with a new Context if set, otherwise f
+func (w *Wyrm) call(ctx context.Context, f func() error, fc func(*Context) error) error {
+	if fc == nil {
+		return f()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w.setCancelCommand(cancel)
+	defer w.setCancelCommand(nil)
+
+	return fc(&Context{
+		Context: ctx,
+		Wyrm:    w,
+		Command: w.state.cmd,
+		Key:     w.state.key,
+		Path:    append([]rune{}, w.state.path...),
+		Count:   w.state.count,
+	})
+}
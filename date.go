@@ -0,0 +1,163 @@
+// Package wyrm date input
+package wyrm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Now returns the reference time for relative dates, e.g. "tomorrow".
+// Can be replaced, e.g. in tests.
+var Now = time.Now
+
+// Regexps for date strings
+var (
+	reISODate  = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	reMonthDay = regexp.MustCompile(`^(\d{2})(\d{2})$`)
+	reRelDays  = regexp.MustCompile(`^([+-]\d+)([dw])$`)
+	reClock    = regexp.MustCompile(`^\d{2}:?\d{2}$`)
+)
+
+// weekdays maps weekday names and abbreviations to weekdays
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		weekdays[name] = d
+		weekdays[name[:3]] = d
+	}
+}
+
+// InputDate read a date input formatted as YYYY-MM-DD or MMDD, or relative
+// to today as "today", "tomorrow", "yesterday", "+3d", "-1w", a weekday
+// like "mon" (today or the next monday) or "next fri" (a week later).
+// Return the date at midnight and any additional characters as tail
+func InputDate(p, def string) (date time.Time, tail string, err error) {
+	return current().InputDate(p, def)
+}
+
+// InputDate is InputDate on the terminal of w
+func (w *Wyrm) InputDate(p, def string) (date time.Time, tail string, err error) {
+	parse := func(s string) (time.Time, string, error) { return parseDate(s, Now()) }
+	t, err := InputOn(w, p, def, TextOptions{History: HistoryDate}, withTail(parse))
+	return t.v, t.tail, err
+}
+
+// InputDateTime read a date, as for InputDate, followed by a time formatted
+// as HH:MM or HHMM. The date can be left out for today.
+// Return the date and time and any additional characters as tail
+func InputDateTime(p, def string) (date time.Time, tail string, err error) {
+	return current().InputDateTime(p, def)
+}
+
+// InputDateTime is InputDateTime on the terminal of w
+func (w *Wyrm) InputDateTime(p, def string) (date time.Time, tail string, err error) {
+	parse := func(s string) (time.Time, string, error) { return parseDateTime(s, Now()) }
+	t, err := InputOn(w, p, def, TextOptions{History: HistoryDate}, withTail(parse))
+	return t.v, t.tail, err
+}
+
+// parseDateTime parses a date, or today if left out, and a time from s.
+// Returns the date and time and the tail.
+func parseDateTime(s string, now time.Time) (time.Time, string, error) {
+	// A time alone, e.g. 0930, could also be taken as MMDD
+	first, rest := splitWord(s)
+	second, _ := splitWord(rest)
+
+	date, tail := midnight(now), strings.TrimSpace(s)
+	if !reClock.MatchString(first) || reClock.MatchString(second) {
+		var err error
+		if date, tail, err = parseDate(s, now); err != nil {
+			return time.Time{}, "", err
+		}
+	}
+
+	word, rest := splitWord(tail)
+	if !reClock.MatchString(word) {
+		return time.Time{}, "", ErrNoTime
+	}
+	h, m, _, err := parseHourMin(word)
+	if err != nil {
+		return time.Time{}, "", ErrNoTime
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, date.Location()), rest, nil
+}
+
+// parseDate parses a date, absolute or relative to now, first in s.
+// Returns the date at midnight and the tail.
+func parseDate(s string, now time.Time) (time.Time, string, error) {
+	today := midnight(now)
+	word, tail := splitWord(s)
+	word = strings.ToLower(word)
+
+	switch word {
+	case "today":
+		return today, tail, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), tail, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), tail, nil
+	case "next":
+		word, tail = splitWord(tail)
+		if d, ok := weekdays[strings.ToLower(word)]; ok {
+			return nextWeekday(today, d).AddDate(0, 0, 7), tail, nil
+		}
+		return time.Time{}, "", ErrNoDate
+	}
+
+	if d, ok := weekdays[word]; ok {
+		return nextWeekday(today, d), tail, nil
+	}
+
+	if ms := reRelDays.FindStringSubmatch(word); ms != nil {
+		n, _ := strconv.Atoi(ms[1])
+		if ms[2] == "w" {
+			n *= 7
+		}
+		return today.AddDate(0, 0, n), tail, nil
+	}
+
+	year, month, day := today.Year(), 0, 0
+	if ms := reISODate.FindStringSubmatch(word); ms != nil {
+		year, _ = strconv.Atoi(ms[1])
+		month, _ = strconv.Atoi(ms[2])
+		day, _ = strconv.Atoi(ms[3])
+	} else if ms := reMonthDay.FindStringSubmatch(word); ms != nil {
+		month, _ = strconv.Atoi(ms[1])
+		day, _ = strconv.Atoi(ms[2])
+	} else {
+		return time.Time{}, "", ErrNoDate
+	}
+
+	// Reject dates normalized by time.Date, e.g. February 30
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, now.Location())
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, "", ErrNoDate
+	}
+
+	return date, tail, nil
+}
+
+// midnight returns the start of the day of t
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextWeekday returns the first day from today that is a d
+func nextWeekday(today time.Time, d time.Weekday) time.Time {
+	return today.AddDate(0, 0, (int(d)-int(today.Weekday())+7)%7)
+}
+
+// splitWord returns the first word of s and the trimmed rest
+func splitWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
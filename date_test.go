@@ -0,0 +1,104 @@
+package wyrm
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // for the daylight saving time cases
+)
+
+// testNow is a wednesday
+var testNow = time.Date(2024, 1, 3, 15, 4, 5, 0, time.UTC)
+
+func testDate(y int, m time.Month, d, h, min int) time.Time {
+	return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+}
+
+func TestParseDate(t *testing.T) {
+	cases := []struct {
+		s    string
+		date time.Time
+		tail string
+		err  error
+	}{
+		{"2024-02-29", testDate(2024, 2, 29, 0, 0), "", nil},
+		{"2024-02-30", time.Time{}, "", ErrNoDate},
+		{"1224 christmas eve", testDate(2024, 12, 24, 0, 0), "christmas eve", nil},
+		{"1324", time.Time{}, "", ErrNoDate},
+		{"today", testDate(2024, 1, 3, 0, 0), "", nil},
+		{" Tomorrow  lunch ", testDate(2024, 1, 4, 0, 0), "lunch", nil},
+		{"yesterday", testDate(2024, 1, 2, 0, 0), "", nil},
+		{"+3d", testDate(2024, 1, 6, 0, 0), "", nil},
+		{"-1w", testDate(2023, 12, 27, 0, 0), "", nil},
+		{"wed", testDate(2024, 1, 3, 0, 0), "", nil},
+		{"mon", testDate(2024, 1, 8, 0, 0), "", nil},
+		{"friday", testDate(2024, 1, 5, 0, 0), "", nil},
+		{"next fri meeting", testDate(2024, 1, 12, 0, 0), "meeting", nil},
+		{"next", time.Time{}, "", ErrNoDate},
+		{"someday", time.Time{}, "", ErrNoDate},
+		{"", time.Time{}, "", ErrNoDate},
+	}
+
+	for _, c := range cases {
+		date, tail, err := parseDate(c.s, testNow)
+		if err != c.err {
+			t.Errorf("parseDate(%q) error %q, expected %q", c.s, err, c.err)
+			continue
+		}
+		if !date.Equal(c.date) || tail != c.tail {
+			t.Errorf("parseDate(%q) = (%v, %q), expected (%v, %q)", c.s, date, tail, c.date, c.tail)
+		}
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	cases := []struct {
+		s    string
+		date time.Time
+		tail string
+		err  error
+	}{
+		{"2024-02-29 12:34", testDate(2024, 2, 29, 12, 34), "", nil},
+		{"tomorrow 0930 standup", testDate(2024, 1, 4, 9, 30), "standup", nil},
+		{"0930", testDate(2024, 1, 3, 9, 30), "", nil},
+		{"12:34 lunch", testDate(2024, 1, 3, 12, 34), "lunch", nil},
+		{"1224 1800", testDate(2024, 12, 24, 18, 0), "", nil},
+		{"today", time.Time{}, "", ErrNoTime},
+		{"today 25:00", time.Time{}, "", ErrNoTime},
+		{"someday 12:00", time.Time{}, "", ErrNoDate},
+	}
+
+	for _, c := range cases {
+		date, tail, err := parseDateTime(c.s, testNow)
+		if err != c.err {
+			t.Errorf("parseDateTime(%q) error %q, expected %q", c.s, err, c.err)
+			continue
+		}
+		if !date.Equal(c.date) || tail != c.tail {
+			t.Errorf("parseDateTime(%q) = (%v, %q), expected (%v, %q)", c.s, date, tail, c.date, c.tail)
+		}
+	}
+}
+
+func TestParseDateTimeDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Fatalf("LoadLocation error %q", err)
+	}
+
+	cases := []struct {
+		now  time.Time
+		s    string
+		date time.Time
+	}{
+		{time.Date(2026, 3, 29, 12, 0, 0, 0, loc), "today 09:30", time.Date(2026, 3, 29, 9, 30, 0, 0, loc)},
+		{time.Date(2026, 10, 25, 12, 0, 0, 0, loc), "today 09:30", time.Date(2026, 10, 25, 9, 30, 0, 0, loc)},
+		{time.Date(2026, 3, 28, 12, 0, 0, 0, loc), "tomorrow 0200", time.Date(2026, 3, 29, 3, 0, 0, 0, loc)},
+	}
+
+	for _, c := range cases {
+		date, _, err := parseDateTime(c.s, c.now)
+		if err != nil || !date.Equal(c.date) {
+			t.Errorf("parseDateTime(%q) at %v = (%v, %v), expected %v", c.s, c.now, date, err, c.date)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package wyrm duration input
+package wyrm
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Regexps for duration strings
+var (
+	reHourColonMin = regexp.MustCompile(`^(\d+):(\d{2})$`)
+	reHourAndMin   = regexp.MustCompile(`^\d+h\d+$`)
+	reMinutes      = regexp.MustCompile(`^\d+$`)
+	reHourMinUnits = regexp.MustCompile(`^(\d+(\.\d+)?h)?(\d+(\.\d+)?m)?$`)
+)
+
+// maxMinutes is the number of minutes of the longest time.Duration
+const maxMinutes = math.MaxInt64 / int64(time.Minute)
+
+// InputDuration read a duration formatted as 1h30, 1h30m, 90m, 2.5h, 1:30
+// or minutes as a plain number. Negative durations and units smaller than
+// minutes aren't accepted.
+// Return the duration and any additional characters as tail
+func InputDuration(p, def string) (d time.Duration, tail string, err error) {
+	return current().InputDuration(p, def)
+}
+
+// InputDuration is InputDuration on the terminal of w
+func (w *Wyrm) InputDuration(p, def string) (d time.Duration, tail string, err error) {
+	t, err := InputOn(w, p, def, TextOptions{History: HistoryTime}, withTail(parseDuration))
+	return t.v, t.tail, err
+}
+
+// InputDurationRange read a duration as InputDuration in the range min to max
+func InputDurationRange(p, def string, min, max time.Duration) (d time.Duration, tail string, err error) {
+	return current().InputDurationRange(p, def, min, max)
+}
+
+// InputDurationRange is InputDurationRange on the terminal of w
+func (w *Wyrm) InputDurationRange(p, def string, min, max time.Duration) (d time.Duration, tail string, err error) {
+	t, err := InputOn(w, p, def, TextOptions{History: HistoryTime}, withTail(parseDuration), validValue(InRange(min, max)))
+	return t.v, t.tail, err
+}
+
+// parseDuration parses the duration first in s and returns it and the tail
+func parseDuration(s string) (time.Duration, string, error) {
+	word, tail := splitWord(s)
+
+	switch {
+	case reHourColonMin.MatchString(word):
+		ms := reHourColonMin.FindStringSubmatch(word)
+		h, err := strconv.ParseInt(ms[1], 10, 64)
+		m, _ := strconv.ParseInt(ms[2], 10, 64)
+		if err != nil || m > 59 || h > (maxMinutes-m)/60 {
+			return 0, "", ErrNoDuration
+		}
+		return time.Duration(h*60+m) * time.Minute, tail, nil
+	case reMinutes.MatchString(word):
+		m, err := strconv.ParseInt(word, 10, 64)
+		if err != nil || m > maxMinutes {
+			return 0, "", ErrNoDuration
+		}
+		return time.Duration(m) * time.Minute, tail, nil
+	case reHourAndMin.MatchString(word):
+		word += "m"
+	}
+
+	// Only positive hours and minutes, no seconds or less
+	if !reHourMinUnits.MatchString(word) {
+		return 0, "", ErrNoDuration
+	}
+
+	d, err := time.ParseDuration(word)
+	if err != nil {
+		return 0, "", ErrNoDuration
+	}
+
+	return d, tail, nil
+}
@@ -0,0 +1,50 @@
+package wyrm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		s    string
+		d    time.Duration
+		tail string
+		err  error
+	}{
+		{"1h30", 90 * time.Minute, "", nil},
+		{"1h30m", 90 * time.Minute, "", nil},
+		{"90m", 90 * time.Minute, "", nil},
+		{"1:30 meeting", 90 * time.Minute, "meeting", nil},
+		{"2.5h", 150 * time.Minute, "", nil},
+		{"45", 45 * time.Minute, "", nil},
+		{" 1.5m ", 90 * time.Second, "", nil},
+		{"-5m", 0, "", ErrNoDuration},
+		{"10s", 0, "", ErrNoDuration},
+		{"100ns", 0, "", ErrNoDuration},
+		{"1h-30m", 0, "", ErrNoDuration},
+		{"1:60", 0, "", ErrNoDuration},
+		{"153722867", 153722867 * time.Minute, "", nil},
+		{"153722868", 0, "", ErrNoDuration},
+		{"200000000", 0, "", ErrNoDuration},
+		{"99999999999999999999", 0, "", ErrNoDuration},
+		{"2562047:47", 153722867 * time.Minute, "", nil},
+		{"2562047:48", 0, "", ErrNoDuration},
+		{"4000000:00", 0, "", ErrNoDuration},
+		{"3000000h", 0, "", ErrNoDuration},
+		{"1h30x", 0, "", ErrNoDuration},
+		{"long", 0, "", ErrNoDuration},
+		{"", 0, "", ErrNoDuration},
+	}
+
+	for _, c := range cases {
+		d, tail, err := parseDuration(c.s)
+		if err != c.err {
+			t.Errorf("parseDuration(%q) error %q, expected %q", c.s, err, c.err)
+			continue
+		}
+		if d != c.d || tail != c.tail {
+			t.Errorf("parseDuration(%q) = (%v, %q), expected (%v, %q)", c.s, d, tail, c.d, c.tail)
+		}
+	}
+}
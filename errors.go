@@ -14,11 +14,57 @@ var ErrDone = fmt.Errorf("done")
 // ErrAbort indicates that the user wants to abort input
 var ErrAbort = fmt.Errorf("abort")
 
+// ErrQuit is returned by a command to make Run return
+var ErrQuit = fmt.Errorf("quit")
+
+// ErrSignal is returned by Run when ended by SIGINT or SIGTERM
+var ErrSignal = fmt.Errorf("ended by signal")
+
+// errTimeout is returned when a key isn't pressed in time
+var errTimeout = fmt.Errorf("timeout")
+
+// errReadCanceled is returned by a terminal read ended by cancelRead
+var errReadCanceled = fmt.Errorf("read canceled")
+
 // ErrNoNumber is returned if number input isn't a number
 var ErrNoNumber = fmt.Errorf("not a number")
 
 // ErrOutOfRange is returned if number input is out of a specific range
 var ErrOutOfRange = fmt.Errorf("out of range")
 
+// RangeError is returned for input out of the range Min to Max.
+// Matches ErrOutOfRange with errors.Is.
+type RangeError[T any] struct {
+	Value, Min, Max T
+}
+
+// Error returns the message with the range
+func (e *RangeError[T]) Error() string {
+	return fmt.Sprintf("%v is out of range %v to %v", e.Value, e.Min, e.Max)
+}
+
+// Is returns true for ErrOutOfRange
+func (e *RangeError[T]) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
 // ErrNoTime is returned of entered time that doesn't mach HH:MM or HHMM
 var ErrNoTime = fmt.Errorf("not a valid time value")
+
+// ErrNoDuration is returned for entered durations that can't be parsed
+var ErrNoDuration = fmt.Errorf("not a valid duration value")
+
+// ErrNoDate is returned for entered dates that can't be parsed
+var ErrNoDate = fmt.Errorf("not a valid date value")
+
+// ErrNoIndex is returned for runes that can't be used as indices
+var ErrNoIndex = fmt.Errorf("not a valid index rune")
+
+// ErrUnknownKey is returned for key names that can't be parsed
+var ErrUnknownKey = fmt.Errorf("unknown key")
+
+// ErrDuplicateKey is returned when loading commands with the same key
+var ErrDuplicateKey = fmt.Errorf("duplicate key")
+
+// ErrUnknownFunction is returned when loading commands with functions missing in the Registry
+var ErrUnknownFunction = fmt.Errorf("unknown function")
@@ -14,16 +14,17 @@ var helloCmd = wyrm.Command{
 	Title:       "hello",
 	Description: "print hello world",
 	Sort:        1, // want this to be first
-	Function:    func() error { fmt.Println("hello world!"); return nil },
-	Pre:         func() error { fmt.Println("(pre command printed this)"); return nil },
-	Post:        func() error { fmt.Println("(post command printed this)"); return nil },
+	Function:    func() error { wyrm.Println("hello world!"); return nil },
+	Pre:         func() error { wyrm.Println("(pre command printed this)"); return nil },
+	Post:        func() error { wyrm.Println("(post command printed this)"); return nil },
 }
 
 // abortCmd always returns ErrAbout
 var abortCmd = wyrm.Command{
 	Title:       "abort",
 	Description: "prints message and returns ErrAbout",
-	Function:    func() error { fmt.Println("aborting"); return wyrm.ErrAbort },
+	Confirm:     "really abort",
+	Function:    func() error { wyrm.Println("aborting"); return wyrm.ErrAbort },
 }
 
 // inputTimeCmd prompts the user for a time and prints it
@@ -38,14 +39,14 @@ var inputTimeCmd = wyrm.Command{
 var errorCmd = wyrm.Command{
 	Title:       "errors",
 	Description: "select what error to show",
-	Function:    func() error { fmt.Println("Press <space> for an overriden global command"); return nil },
+	Function:    func() error { wyrm.Println("Press <space> for an overriden global command"); return nil },
 	Commands: map[rune]*wyrm.Command{
 		'<': {
 			Title:       "pre",
 			Description: "Show a Pre function error",
 			Pre:         func() error { return fmt.Errorf("planned Pre Error") },
 			Sort:        1,
-			Function:    func() error { fmt.Println("This should not be shown"); return nil },
+			Function:    func() error { wyrm.Println("This should not be shown"); return nil },
 		},
 		'c': {
 			Title:       "command",
@@ -58,7 +59,7 @@ var errorCmd = wyrm.Command{
 			Description: "Show a Post function error",
 			Post:        func() error { return fmt.Errorf("planned Post Error") },
 			Sort:        3,
-			Function:    func() error { fmt.Println("Correct output"); return nil },
+			Function:    func() error { wyrm.Println("Correct output"); return nil },
 		},
 		wyrm.RuneSpace: { // override wyrm global command
 			Title:       "extra",
@@ -75,8 +76,8 @@ func main() {
 	cmds := wyrm.Command{
 		Title:       "wyrm",
 		Description: "wyrm example program",
-		Pre:         func() error { fmt.Println("Root Pre (could clear screen)"); return nil },
-		Commands: map[rune]*wyrm.Command{
+		Pre:         func() error { wyrm.Println("Root Pre (could clear screen)"); return nil },
+		Commands: map[wyrm.Key]*wyrm.Command{
 			'i': {
 				Title:       "input",
 				Description: "input different stuff using sub commands",
@@ -87,40 +88,87 @@ func main() {
 						Title:       "string",
 						Description: "input a string",
 						Function:    inputText,
-						Post:        func() error { fmt.Println("text was inputted"); return nil },
+						Post:        func() error { wyrm.Println("text was inputted"); return nil },
 					},
 					'n': {
 						Sort:        2,
 						Title:       "number",
 						Description: "input a number",
 						Function:    inputNumber,
-						Pre:         func() error { fmt.Println("pre number selection"); return nil },
+						Pre:         func() error { wyrm.Println("pre number selection"); return nil },
 					},
 					't': &inputTimeCmd,
+					'x': {
+						Sort:        4,
+						Title:       "indexed",
+						Description: "select by index rune",
+						Function:    inputIndexed,
+					},
+					'd': {
+						Sort:        6,
+						Title:       "date",
+						Description: "input a date, e.g. 2024-12-24, tomorrow or next fri",
+						Function:    inputDate,
+					},
+					'p': {
+						Sort:        7,
+						Title:       "password",
+						Description: "input a secret without echo",
+						Function:    inputSecret,
+					},
+					'l': {
+						Sort:        8,
+						Title:       "lines",
+						Description: "input several lines, C-x opens an editor",
+						Function:    inputLines,
+					},
+					'm': {
+						Sort:        5,
+						Title:       "multi",
+						Description: "select several items",
+						Function:    inputMulti,
+					},
 				},
 			},
 			's': {
 				Title:       "select",
-				Description: "select by index",
+				Description: "select from a filterable list",
 				Sort:        2, // want this to be second
 				Function:    selectIndex,
 			},
-			'h': &helloCmd, // Sort: 1
-			'e': &errorCmd, // Sort: 0 -> put at the end somewhere
+			'w': {
+				Title:       "wait",
+				Description: "wait until cancelled with Ctrl-C",
+				FunctionCtx: waitForCancel,
+			},
+			'l': {
+				Title:       "list",
+				Description: "list files in a directory using a shell command",
+				Shell:       `ls -l {{input "directory"}}`,
+			},
+			'h':            &helloCmd, // Sort: 1
+			wyrm.Alt('h'):  &helloCmd, // M-h says hello too
+			wyrm.Ctrl('t'): &inputTimeCmd,
+			'e':            &errorCmd, // Sort: 0 -> put at the end somewhere
+		},
+		Chords: map[string]*wyrm.Command{
+			"tt": &inputTimeCmd, // same as i t, without the input command
 		},
 	}
 
-	// Create Wyrm
-	w = wyrm.New(&cmds)
+	// Create Wyrm, saving input history between runs
+	w = wyrm.New(&cmds, wyrm.WithHistory(wyrm.DefaultHistoryLimit, wyrm.HistoryDir("wyrm-example")))
 
-	fmt.Println("Wyrm Example")
-	fmt.Println("use q to quit and ? for help")
+	wyrm.Println("Wyrm Example")
+	wyrm.Println("use q to quit and ? for help")
 
 	// Manually run Pre command for root
 	w.GetCurrentCommand().Pre()
 
 	// Run Wyrm
-	w.Run()
+	if err := w.Run(); err != nil {
+		fmt.Printf("Error: %s\n", err)
+	}
 }
 
 func inputText() error {
@@ -129,18 +177,18 @@ func inputText() error {
 		return err
 	}
 
-	fmt.Printf("Your entered: %q\n", input)
+	wyrm.Printf("Your entered: %q\n", input)
 
 	return nil
 }
 
 func inputNumber() error {
-	input, err := wyrm.InputInt(w.InputPrompt("enter number less than 10"), "", 10)
+	input, err := wyrm.InputIntRange(w.InputPrompt("enter number, -10k to 10k"), "", -10000, 10000)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Your entered: %v\n", input)
+	wyrm.Printf("Your entered: %v\n", input)
 
 	return nil
 }
@@ -151,33 +199,98 @@ func inputTime() error {
 		return err
 	}
 
-	fmt.Printf("Your entered: %v\n", input)
+	wyrm.Printf("Your entered: %v\n", input)
 
 	return nil
 }
 
-func selectIndex() error {
-	options := map[rune]string{
-		'1': "one option",
-		'a': "another option",
+func inputDate() error {
+	date, tail, err := wyrm.InputDate(w.InputPrompt("date"), "today")
+	if err != nil {
+		return err
+	}
+
+	wyrm.Printf("Your entered: %v %q\n", date.Format("Mon 2006-01-02"), tail)
+
+	return nil
+}
+
+func inputSecret() error {
+	secret, err := wyrm.InputSecret(w.InputPrompt("password"))
+	if err != nil {
+		return err
+	}
+
+	wyrm.Printf("Your entered %d bytes\n", len(secret))
+
+	// Don't keep the secret in memory longer than needed
+	for i := range secret {
+		secret[i] = 0
+	}
+
+	return nil
+}
+
+func inputLines() error {
+	text, err := wyrm.InputMultiline(w.InputPrompt("end with . or C-d, C-x for editor"), "")
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("Select:")
-	for k, v := range options {
-		fmt.Printf("  %v: %v\n", string(k), v)
+	wyrm.Printf("Your entered:\n%s\n", text)
+
+	return nil
+}
+
+func inputIndexed() error {
+	options := []string{"first", "second", "third"}
+
+	i, err := wyrm.InputIndexed(w.RunePrompt("select index"), options)
+	if err != nil {
+		return err
 	}
 
-	r, err := wyrm.InputRune(w.InputPrompt("select index"))
+	wyrm.Printf("You selected %q\n", options[i])
+
+	return nil
+}
+
+func inputMulti() error {
+	options := []string{"red", "green", "blue"}
+
+	is, err := wyrm.InputMultiSelect(w.RunePrompt("toggle, + all, - none"), options, []int{1})
 	if err != nil {
 		return err
 	}
 
-	v, ok := options[r]
-	if !ok {
-		return fmt.Errorf("no match for %q", string(r))
+	for _, i := range is {
+		wyrm.Printf("You selected %q\n", options[i])
+	}
+
+	return nil
+}
+
+func waitForCancel(ctx *wyrm.Context) error {
+	wyrm.Printf("Waiting in %q, press Ctrl-C to cancel\n", string(ctx.Path))
+	<-ctx.Done()
+	wyrm.Println("Cancelled")
+
+	return nil
+}
+
+func selectIndex() error {
+	options := []string{
+		"one option",
+		"another option",
+		"yet another option",
+	}
+
+	i, v, err := wyrm.InputSelect(w.InputPrompt("type to filter"), options)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("You selected %q\n", v)
+	wyrm.Printf("You selected %q (%d)\n", v, i)
 
 	return nil
 }
@@ -3,10 +3,9 @@ package wyrm
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
 	"sort"
-	"strings"
+	"unicode"
 )
 
 // Special keys/runes
@@ -26,7 +25,7 @@ var globalKeyInfo = map[rune][2]string{
 	RuneSpace:  {"space", "show key info for current command"},
 	RuneEnter:  {"newline", "show key info recursivly"},
 	RuneScream: {"!", "execute shell command"},
-	RuneClear:  {"ctrl-l", "clear screen"},
+	RuneClear:  {"C-l", "clear screen"},
 	RuneEsc:    {"escape", "abort input"},
 	RuneQue:    {"?", "display detailed help"},
 	RuneQuit:   {"q", "quit program nicely"},
@@ -49,15 +48,15 @@ func (w *Wyrm) getGlobalCommands() map[rune]*Command {
 		},
 		RuneClear: { // ctrl-l to clear screen
 			Description: globalKeyInfo[RuneClear][1],
-			Function:    func() error { fmt.Printf(string("\x1b[2J\x1b[H")); return nil },
+			Function:    func() error { fmt.Fprint(w.term, "\x1b[2J\x1b[H"); return nil },
 		},
 		RuneEsc: { // esc for abort
 			Description: globalKeyInfo[RuneEsc][1],
-			Function:    func() error { w.state.cmd = w.rootCommand; return nil },
+			Function:    func() error { w.toRoot(); return nil },
 		},
 		RuneScream: { // exclamation mark to execute shell command
 			Description: globalKeyInfo[RuneScream][1],
-			Function:    w.shellCommand,
+			FunctionCtx: w.shellCommand,
 		},
 		RuneQuit: { // q to exit
 			Description: globalKeyInfo[RuneQuit][1],
@@ -68,7 +67,7 @@ func (w *Wyrm) getGlobalCommands() map[rune]*Command {
 
 // commandsHelpCommand prints help about the commands
 func (w *Wyrm) commandsHelpCommand(recursive bool) error {
-	fmt.Printf("Available command keys:\n")
+	fmt.Fprintf(w.term, "Available command keys:\n")
 
 	pad := "    "
 
@@ -77,22 +76,31 @@ func (w *Wyrm) commandsHelpCommand(recursive bool) error {
 	p = func(cmd *Command, indent string) {
 		states := []state{}
 		for k, c := range cmd.Commands {
-			states = append(states, state{k, c})
+			states = append(states, state{key: k, cmd: c})
 		}
 
 		sort.Sort(stateByOrder(states))
 
 		for _, s := range states {
 			// fmt.Println("state:", string(s.key))
-			key := string(s.key)
-			if info, ok := globalKeyInfo[s.key]; ok {
-				key = info[0]
-			}
-			fmt.Printf(indent+"[%s] %q - %s\n", key, s.cmd.Title, s.cmd.Description)
+			fmt.Fprintf(w.term, indent+"[%s] %q - %s\n", KeyName(s.key), s.cmd.Title, s.cmd.Description)
 			if recursive {
 				p(s.cmd, indent+pad)
 			}
 		}
+
+		chords := []string{}
+		for c := range cmd.Chords {
+			chords = append(chords, c)
+		}
+		sort.Strings(chords)
+
+		for _, c := range chords {
+			fmt.Fprintf(w.term, indent+"[%s] %q - %s\n", c, cmd.Chords[c].Title, cmd.Chords[c].Description)
+			if recursive {
+				p(cmd.Chords[c], indent+pad)
+			}
+		}
 	}
 
 	// Print recursivly from current command
@@ -104,70 +112,65 @@ func (w *Wyrm) commandsHelpCommand(recursive bool) error {
 // detailedHelpCommand prints help about commands and global commands
 func (w *Wyrm) detailedHelpCommand() error {
 	w.commandsHelpCommand(true)
-	fmt.Printf("Global command keys:\n")
+	fmt.Fprintf(w.term, "Global command keys:\n")
 	for r := range w.getGlobalCommands() {
 		text := globalKeyInfo[r][1]
 		if _, exists := w.state.cmd.Commands[r]; exists {
 			text = "overridden for current command"
 		}
-		fmt.Printf("%12s - %s\n", "["+globalKeyInfo[r][0]+"]", text)
+		fmt.Fprintf(w.term, "%12s - %s\n", "["+globalKeyInfo[r][0]+"]", text)
 	}
 	return nil
 }
 
 // shellCommand makes it possible to execute shell commands
-func (w *Wyrm) shellCommand() error {
+func (w *Wyrm) shellCommand(ctx *Context) error {
 
 	// Get command line
-	line, err := InputText(w.InputPrompt("enter shell command"), "")
+	line, err := w.InputTextWith(w.InputPrompt("enter shell command"), "", TextOptions{
+		History:   HistoryShell,
+		Completer: CompleteExecutables,
+	})
+	if err == ErrEmpty {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	parts := strings.Split(line, " ")
-	bs, err := exec.Command(parts[0], parts[1:]...).Output()
-	if err != nil {
+	code := 0
+	err = w.runShell(ctx, w.shell, line)
+	if exit, ok := err.(*exec.ExitError); ok {
+		code = exit.ExitCode()
+	} else if err != nil {
 		return err
 	}
 
-	fmt.Printf("%v", string(bs))
-
-	return err
+	fmt.Fprintf(w.term, "[exit status %d]\n", code)
+	return nil
 }
 
 // quitCommand is executed to leave program
 func (w *Wyrm) quitCommand() error {
-	fmt.Printf("bye!\n")
-
-	// This does not work on Darwin
-	// But command works in a Darwin terminal so an alias like this:
-	//   alias bu='clear && bujogo && reset'
-	// can be helpful
-	exec.Command("reset").Run()
-
-	os.Exit(0)
-	return nil
+	fmt.Fprintf(w.term, "bye!\n")
+	return ErrQuit
 }
 
-// isSpecialKey returns true if input rune is a member of global key info
+// isSpecialKey returns true if input rune is a member of global key info,
+// a named key, a control character or has a modifier
 func isSpecialKey(r rune) bool {
-	for k := range globalKeyInfo {
-		if r == k {
-			return true
-		}
+	if _, ok := globalKeyInfo[r]; ok {
+		return true
 	}
-	return false
+	_, ok := keyNames[r]
+	return ok || r&ModAlt != 0 || unicode.IsControl(r)
 }
 
 // specialKeys returns the special keys
 func specialKeys(c map[rune]*Command) []string {
 	keys := []string{}
 	for k := range c {
-		s := string(k)
-		if isSpecialKey(k) {
-			s = globalKeyInfo[k][0]
-		}
-		keys = append(keys, s)
+		keys = append(keys, KeyName(k))
 	}
 
 	sort.Strings(keys)
@@ -2,6 +2,7 @@ module github.com/callerobertsson/wyrm
 
 go 1.19
 
-require github.com/chzyer/readline v1.5.1
-
-require golang.org/x/sys v0.0.0-20220310020820-b874c991c1a5 // indirect
+require (
+	github.com/chzyer/readline v1.5.1
+	golang.org/x/sys v0.15.0
+)
@@ -0,0 +1,132 @@
+// Package wyrm input history
+package wyrm
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/chzyer/readline"
+)
+
+// History kinds, each kind of prompt has its own history
+const (
+	HistoryNone   = ""
+	HistoryText   = "text"
+	HistoryShell  = "shell"
+	HistoryTime   = "time"
+	HistoryDate   = "date"
+	HistoryNumber = "number"
+)
+
+// DefaultHistoryLimit is the default max number of entries per history kind
+const DefaultHistoryLimit = 100
+
+// historyConfig holds the history settings
+type historyConfig struct {
+	limit int    // max entries per kind, negative to disable history
+	dir   string // directory for history files, empty for no files
+}
+
+// WithHistory sets the max number of entries per history kind and the
+// directory to save them in. Use a negative limit to disable history and
+// an empty dir to not save it, see HistoryDir.
+func WithHistory(limit int, dir string) Option {
+	return func(w *Wyrm) {
+		w.history = historyConfig{limit, dir}
+	}
+}
+
+// HistoryDir returns the directory for the history files of app,
+// in $XDG_STATE_HOME or ~/.local/state
+func HistoryDir(app string) string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, app)
+}
+
+// lineEditor returns the line editor for the history kind, creating it if needed
+func (w *Wyrm) lineEditor(kind string) (*readline.Instance, error) {
+	if r, ok := w.lines[kind]; ok && kind != HistoryNone {
+		return r, nil
+	}
+
+	r, err := w.newLineEditor(kind, lineReader{w})
+	if err != nil {
+		return nil, err
+	}
+
+	if kind != HistoryNone {
+		w.lines[kind] = r
+	}
+	return r, nil
+}
+
+// lineReader reads the unread bytes of a Wyrm one at a time, so a line
+// editor reading from it never reads ahead of what it uses
+type lineReader struct {
+	w *Wyrm
+}
+
+// Read reads at most one byte into p.
+// Returns ErrSignal if Run ends on a signal while waiting.
+func (l lineReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	kr := l.w.keys
+	kr.flushKeys()
+	for len(kr.unread) == 0 {
+		if err := l.w.fill(context.Background(), nil, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	p[0], kr.unread = kr.unread[0], kr.unread[1:]
+	return 1, nil
+}
+
+// newLineEditor returns a new line editor for the history kind reading from in
+func (w *Wyrm) newLineEditor(kind string, in io.Reader) (*readline.Instance, error) {
+	cfg := &readline.Config{
+		Stdin:        io.NopCloser(in),
+		Stdout:       w.term,
+		HistoryLimit: w.history.limit,
+	}
+	if kind == HistoryNone {
+		cfg.HistoryLimit = -1
+	}
+	if w.history.dir != "" && cfg.HistoryLimit > 0 {
+		if err := os.MkdirAll(w.history.dir, 0o700); err != nil {
+			return nil, err
+		}
+		cfg.HistoryFile = filepath.Join(w.history.dir, kind+"_history")
+	}
+	if _, ok := w.term.(*stdTerminal); !ok {
+		// Other terminals are expected to handle raw mode themselves, and
+		// SIGWINCH is about stdout
+		cfg.FuncIsTerminal = w.term.IsTerminal
+		cfg.FuncMakeRaw = func() error { return nil }
+		cfg.FuncExitRaw = func() error { return nil }
+		cfg.FuncOnWidthChanged = func(func()) {}
+	}
+
+	return readline.NewEx(cfg)
+}
+
+// closeLineEditors closes the line editors, e.g. when Run returns
+func (w *Wyrm) closeLineEditors() {
+	for kind, r := range w.lines {
+		r.Close()
+		delete(w.lines, kind)
+	}
+}
@@ -0,0 +1,19 @@
+package wyrm
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryDir(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	if dir := HistoryDir("app"); dir != filepath.Join("/state", "app") {
+		t.Errorf("HistoryDir = %q, expected %q", dir, "/state/app")
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/user")
+	if dir := HistoryDir("app"); dir != filepath.Join("/home/user", ".local", "state", "app") {
+		t.Errorf("HistoryDir = %q, expected %q", dir, "/home/user/.local/state/app")
+	}
+}
@@ -1,6 +1,10 @@
 // Package wyrm indexing helper
 package wyrm
 
+import (
+	"unicode"
+)
+
 var indices []rune
 
 func init() {
@@ -18,6 +22,25 @@ func init() {
 	}
 }
 
+// SetIndexRunes sets the runes used as indices, e.g. to add local letters.
+// Returns ErrNoIndex if rs is empty or any rune is not printable or is repeated.
+func SetIndexRunes(rs []rune) error {
+	if len(rs) == 0 {
+		return ErrNoIndex
+	}
+
+	seen := map[rune]bool{}
+	for _, r := range rs {
+		if !unicode.IsPrint(r) || r == ' ' || seen[r] {
+			return ErrNoIndex
+		}
+		seen[r] = true
+	}
+
+	indices = append([]rune{}, rs...)
+	return nil
+}
+
 // GetIndexRunes returns the list of runes used as indices
 func GetIndexRunes() []rune {
 	return indices
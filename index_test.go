@@ -69,3 +69,34 @@ func TestIndexRune(t *testing.T) {
 		}
 	}
 }
+
+func TestSetIndexRunes(t *testing.T) {
+	defer SetIndexRunes(GetIndexRunes())
+
+	cases := []struct {
+		rs  string
+		err error
+	}{
+		{"abcåäö", nil},
+		{"ab c", ErrNoIndex},
+		{"ab\x1b", ErrNoIndex},
+		{"abca", ErrNoIndex},
+		{"", ErrNoIndex},
+	}
+
+	for _, c := range cases {
+		err := SetIndexRunes([]rune(c.rs))
+		if err != c.err {
+			t.Errorf("SetIndexRunes(%q) error %q, expected %q", c.rs, err, c.err)
+			continue
+		}
+		if err == nil && string(GetIndexRunes()) != c.rs {
+			t.Errorf("GetIndexRunes = %q, expected %q", string(GetIndexRunes()), c.rs)
+		}
+	}
+
+	i, err := GetRuneIndex('ö')
+	if err != nil || i != 5 {
+		t.Errorf("GetRuneIndex('ö') = (%d, %v), expected (5, nil)", i, err)
+	}
+}
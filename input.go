@@ -2,9 +2,9 @@
 package wyrm
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -15,31 +15,117 @@ import (
 // Regexp for time strings, HH:MM or HHMM
 var reHourMin = regexp.MustCompile(`(\d{2}):?(\d{2})(.*)`)
 
-// InputRune read a single rune
+// InputRune read a single key, a UTF-8 encoded rune or a named key like RuneUp.
+// Returns ErrAbort on Esc or SIGINT and ErrSignal if Run ends on a signal.
 func InputRune(p string) (rune, error) {
-	fmt.Printf(p)
-	buf := make([]byte, 1)
-	os.Stdin.Read(buf)
-	fmt.Println("")
-	r := rune(buf[0])
-	if r == RuneEsc {
-		return r, ErrAbort
+	return current().InputRune(p)
+}
+
+// InputRune is InputRune on the terminal of w
+func (w *Wyrm) InputRune(p string) (rune, error) {
+	fmt.Fprint(w.term, p)
+
+	intr, stop := w.watchInterrupt()
+	defer stop()
+	r, err := w.waitKey(context.Background(), 0, nil, intr)
+	fmt.Fprintln(w.term, "")
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, ErrDone
+	}
+	if err == ErrAbort || err == nil && r == RuneEsc {
+		w.cancelCommand()
+		return RuneEsc, ErrAbort
+	}
+	if err != nil {
+		return 0, err
 	}
 	return r, nil
 }
 
+// InputConfirm asks a yes or no question, with the answer on Enter as def.
+// Other keys than y, n and Enter are ignored. Shows the choices given by
+// YesNo in the prompt, e.g. InputConfirm(w.RunePrompt("delete "+YesNo(false)), false).
+// Returns ErrAbort on Esc or SIGINT, which should be taken as a no.
+func InputConfirm(p string, def bool) (bool, error) {
+	return current().InputConfirm(p, def)
+}
+
+// InputConfirm is InputConfirm on the terminal of w
+func (w *Wyrm) InputConfirm(p string, def bool) (bool, error) {
+	for {
+		r, err := w.InputRune(p)
+		if err != nil {
+			return false, err
+		}
+
+		switch r {
+		case 'y', 'Y':
+			return true, nil
+		case 'n', 'N':
+			return false, nil
+		case RuneEnter:
+			return def, nil
+		}
+	}
+}
+
+// YesNo returns the choices of InputConfirm with def in upper case,
+// [Y/n] or [y/N]
+func YesNo(def bool) string {
+	if def {
+		return "[Y/n]"
+	}
+	return "[y/N]"
+}
+
+// TextOptions are options for InputTextWith
+type TextOptions struct {
+	History   string    // history kind, e.g. HistoryText, or HistoryNone
+	Completer Completer // tab completion, e.g. CompleteFiles, or nil
+}
+
 // InputText prints prompt and reads input from user
 func InputText(p string, def string) (input string, err error) {
-	r, err := readline.New(p)
+	return current().InputText(p, def)
+}
+
+// InputText is InputText on the terminal of w
+func (w *Wyrm) InputText(p string, def string) (input string, err error) {
+	return w.InputTextWith(p, def, TextOptions{History: HistoryText})
+}
+
+// InputTextWith is InputText with options.
+// Entries are kept in the history of the kind, navigable with the arrow
+// keys and searchable with Ctrl-R. Tab completes using the Completer.
+func InputTextWith(p, def string, opts TextOptions) (input string, err error) {
+	return current().InputTextWith(p, def, opts)
+}
+
+// InputTextWith is InputTextWith on the terminal of w
+func (w *Wyrm) InputTextWith(p, def string, opts TextOptions) (input string, err error) {
+	r, err := w.lineEditor(opts.History)
 	if err != nil {
 		return input, err
 	}
+	if opts.History == HistoryNone {
+		defer r.Close()
+	}
+
+	if !w.term.IsTerminal() {
+		// The line editor only prints the prompt on interactive terminals
+		fmt.Fprint(w.term, p)
+	}
+	r.SetPrompt(p)
+	r.Config.AutoComplete = completer(opts.Completer)
 	r.Operation.SetBuffer(def)
 
 	input, err = r.Readline()
 	switch {
 	case err == readline.ErrInterrupt:
+		w.cancelCommand()
 		return input, ErrAbort
+	case err == io.EOF && w.hasSignaled():
+		return input, ErrSignal
 	case err == io.EOF:
 		return input, ErrDone
 	case err != nil:
@@ -51,39 +137,112 @@ func InputText(p string, def string) (input string, err error) {
 	return strings.TrimSpace(input), nil
 }
 
-// InputInt read an integer in the range 0 to max from the user
-func InputInt(p, def string, max int) (i int, err error) {
-	// Read number as string
-	input, err := InputText(p, def)
-	if err != nil {
-		return i, err
+// Validator checks a parsed input value, returning an error to show if invalid
+type Validator[T any] func(T) error
+
+// ordered are the types that can be compared with <
+type ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// InRange returns a Validator returning a RangeError for values outside
+// of the range min to max
+func InRange[T ordered](min, max T) Validator[T] {
+	return func(v T) error {
+		if v < min || v > max {
+			return &RangeError[T]{Value: v, Min: min, Max: max}
+		}
+		return nil
 	}
+}
 
-	// Convert to int
-	i, err = strconv.Atoi(input)
-	if err != nil {
-		return i, ErrNoNumber
+// Input prints prompt and reads input from user, parsed by parse and checked
+// by the validators. Invalid input shows the error and prompts again, with
+// the input to correct, until valid or aborted.
+func Input[T any](p, def string, parse func(string) (T, error), validators ...Validator[T]) (T, error) {
+	return InputWith(p, def, TextOptions{History: HistoryText}, parse, validators...)
+}
+
+// InputWith is Input with options as for InputTextWith
+func InputWith[T any](p, def string, opts TextOptions, parse func(string) (T, error), validators ...Validator[T]) (T, error) {
+	return InputOn(current(), p, def, opts, parse, validators...)
+}
+
+// InputOn is InputWith on the terminal of w
+func InputOn[T any](w *Wyrm, p, def string, opts TextOptions, parse func(string) (T, error), validators ...Validator[T]) (T, error) {
+	for {
+		input, err := w.InputTextWith(p, def, opts)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+
+		v, err := parse(input)
+		for _, validate := range validators {
+			if err != nil {
+				break
+			}
+			err = validate(v)
+		}
+		if err == nil {
+			return v, nil
+		}
+
+		fmt.Fprintf(w.term, "Error: %s\n", err)
+		def = input
+	}
+}
+
+// tailed is a parsed value and the additional characters after it
+type tailed[T any] struct {
+	v    T
+	tail string
+}
+
+// withTail makes a parse function returning a tail usable with Input
+func withTail[T any](parse func(string) (T, string, error)) func(string) (tailed[T], error) {
+	return func(s string) (tailed[T], error) {
+		v, tail, err := parse(s)
+		return tailed[T]{v, tail}, err
 	}
-	if i < 0 || i > max {
-		return i, ErrOutOfRange
+}
+
+// validValue makes validators of values usable for tailed values
+func validValue[T any](validate Validator[T]) Validator[tailed[T]] {
+	return func(t tailed[T]) error {
+		return validate(t.v)
 	}
+}
+
+// InputInt read an integer in the range 0 to max from the user
+func InputInt(p, def string, max int) (i int, err error) {
+	return current().InputInt(p, def, max)
+}
 
-	return i, nil
+// InputInt is InputInt on the terminal of w
+func (w *Wyrm) InputInt(p, def string, max int) (i int, err error) {
+	return w.InputIntRange(p, def, 0, max)
 }
 
 // InputTime read a time input formatted as HH:MM or HHMM
 // Return time as a string and any additional characters as tail
 func InputTime(p, def string) (time, tail string, err error) {
-	// Read input
-	input, err := InputText(p, def)
-	if err != nil {
-		return time, tail, err
-	}
+	return current().InputTime(p, def)
+}
+
+// InputTime is InputTime on the terminal of w
+func (w *Wyrm) InputTime(p, def string) (time, tail string, err error) {
+	t, err := InputOn(w, p, def, TextOptions{History: HistoryTime}, withTail(parseTime))
+	return t.v, t.tail, err
+}
 
-	// Parse hour and minute
-	h, m, tail, err := parseHourMin(input)
+// parseTime parses a time as HH:MM and returns it formatted and the tail
+func parseTime(s string) (string, string, error) {
+	h, m, tail, err := parseHourMin(s)
 	if err != nil {
-		return time, tail, ErrNoTime
+		return "", "", ErrNoTime
 	}
 
 	return fmt.Sprintf("%02d:%02d", h, m), tail, nil
@@ -1,7 +1,12 @@
 package wyrm
 
 import (
+	"context"
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestParseHourMin(t *testing.T) {
@@ -35,3 +40,101 @@ func TestParseHourMin(t *testing.T) {
 		}
 	}
 }
+
+func TestWaitKey(t *testing.T) {
+	cases := []struct {
+		s   string
+		r   rune
+		err error
+	}{
+		{"a", 'a', nil},
+		{"å", 'å', nil},
+		{"ö", 'ö', nil},
+		{"→", '→', nil},
+		{"😀", '😀', nil},
+		{"\x1b", RuneEsc, nil},
+		{"\x1b[A", RuneUp, nil},
+		{"\xff", utf8.RuneError, nil},
+		{"\xe2\x86", 0, io.ErrUnexpectedEOF},
+		{"", 0, io.EOF},
+	}
+
+	for _, c := range cases {
+		w := New(nil, WithTerminal(&testTerminal{Reader: strings.NewReader(c.s)}))
+		r, err := w.waitKey(context.Background(), 0, nil, nil)
+		if err != c.err {
+			t.Errorf("waitKey(%q) error %q, expected %q", c.s, err, c.err)
+			continue
+		}
+		if r != c.r {
+			t.Errorf("waitKey(%q) = %q, expected %q", c.s, r, c.r)
+		}
+	}
+}
+
+// chunkTerminal is an interactive Terminal reading the chunks sent on a
+// channel, reporting when a read is pending on pending, if set
+type chunkTerminal struct {
+	testTerminal
+	chunks  chan string
+	pending chan struct{}
+}
+
+func (t *chunkTerminal) Read(p []byte) (int, error) {
+	if t.pending != nil {
+		t.pending <- struct{}{}
+	}
+	s, ok := <-t.chunks
+	if !ok {
+		return 0, io.EOF
+	}
+	return copy(p, s), nil
+}
+
+func (t *chunkTerminal) IsTerminal() bool { return true }
+
+func TestWaitKeySplit(t *testing.T) {
+	cases := []struct {
+		chunks []string
+		k      Key
+	}{
+		{[]string{"\x1b", "[A"}, RuneUp},
+		{[]string{"\x1b", "[", "1;5C"}, RuneRight},
+		{[]string{"\x1b", "x"}, Alt('x')},
+		{[]string{"\x1b"}, RuneEsc},
+		{[]string{"\x1b["}, Alt('[')},
+	}
+
+	for _, c := range cases {
+		ct := &chunkTerminal{chunks: make(chan string, len(c.chunks))}
+		for _, s := range c.chunks {
+			ct.chunks <- s
+		}
+
+		k, err := New(nil, WithTerminal(ct)).waitKey(context.Background(), 0, nil, nil)
+		close(ct.chunks)
+		if err != nil || k != c.k {
+			t.Errorf("waitKey(%q) = (%q, %v), expected (%q, nil)", c.chunks, k, err, c.k)
+		}
+	}
+}
+
+func TestInRange(t *testing.T) {
+	cases := []struct {
+		v   int
+		err error
+	}{
+		{-1, ErrOutOfRange},
+		{0, nil},
+		{5, nil},
+		{10, nil},
+		{11, ErrOutOfRange},
+	}
+
+	valid := InRange(0, 10)
+	for _, c := range cases {
+		if err := valid(c.v); !errors.Is(err, c.err) {
+			t.Errorf("InRange(0, 10)(%d) = %q, expected %q", c.v, err, c.err)
+		}
+	}
+}
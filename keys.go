@@ -0,0 +1,403 @@
+// Package wyrm key decoding
+package wyrm
+
+import (
+	"io"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key is a key press, a rune, a named key like RuneUp or a control
+// character, possibly with the ModAlt modifier set.
+// It is an alias for rune, so command maps can be keyed by both.
+type Key = rune
+
+// ModAlt is set for keys pressed with Alt (Meta), sent as Esc and the key
+const ModAlt Key = 1 << 24
+
+// Ctrl returns the key for Ctrl and r, e.g. Ctrl('x') is "\x18"
+func Ctrl(r rune) Key {
+	return unicode.ToUpper(r) & 0x1f
+}
+
+// Alt returns the key for Alt and k, e.g. Alt('f')
+func Alt(k Key) Key {
+	return k | ModAlt
+}
+
+// KeyName returns the name of k, e.g. "a", "up", "C-x" or "M-f"
+func KeyName(k Key) string {
+	if k&ModAlt != 0 {
+		return "M-" + KeyName(k&^ModAlt)
+	}
+	if info, ok := globalKeyInfo[k]; ok {
+		return info[0]
+	}
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	if k >= 0 && k < 0x20 {
+		return "C-" + string(unicode.ToLower(k+0x40))
+	}
+	return string(k)
+}
+
+// Named keys decoded from escape sequences, in the Unicode private use area.
+// Can be bound in Command.Commands like any other rune.
+const (
+	RuneUp Key = 0xe000 + iota
+	RuneDown
+	RuneRight
+	RuneLeft
+	RuneHome
+	RuneEnd
+	RuneInsert
+	RuneDelete
+	RunePgUp
+	RunePgDown
+	RuneF1
+	RuneF2
+	RuneF3
+	RuneF4
+	RuneF5
+	RuneF6
+	RuneF7
+	RuneF8
+	RuneF9
+	RuneF10
+	RuneF11
+	RuneF12
+)
+
+// keyNames holds the display names of the named keys
+var keyNames = map[Key]string{
+	RuneUp:     "up",
+	RuneDown:   "down",
+	RuneRight:  "right",
+	RuneLeft:   "left",
+	RuneHome:   "home",
+	RuneEnd:    "end",
+	RuneInsert: "insert",
+	RuneDelete: "delete",
+	RunePgUp:   "pgup",
+	RunePgDown: "pgdown",
+	RuneF1:     "f1",
+	RuneF2:     "f2",
+	RuneF3:     "f3",
+	RuneF4:     "f4",
+	RuneF5:     "f5",
+	RuneF6:     "f6",
+	RuneF7:     "f7",
+	RuneF8:     "f8",
+	RuneF9:     "f9",
+	RuneF10:    "f10",
+	RuneF11:    "f11",
+	RuneF12:    "f12",
+}
+
+// Final bytes of CSI (Esc [) and SS3 (Esc O) sequences
+var finalKeys = map[byte]Key{
+	'A': RuneUp,
+	'B': RuneDown,
+	'C': RuneRight,
+	'D': RuneLeft,
+	'H': RuneHome,
+	'F': RuneEnd,
+	'P': RuneF1,
+	'Q': RuneF2,
+	'R': RuneF3,
+	'S': RuneF4,
+}
+
+// Parameters of CSI sequences ending with ~
+var tildeKeys = map[int]Key{
+	1:  RuneHome,
+	2:  RuneInsert,
+	3:  RuneDelete,
+	4:  RuneEnd,
+	5:  RunePgUp,
+	6:  RunePgDown,
+	7:  RuneHome,
+	8:  RuneEnd,
+	11: RuneF1,
+	12: RuneF2,
+	13: RuneF3,
+	14: RuneF4,
+	15: RuneF5,
+	17: RuneF6,
+	18: RuneF7,
+	19: RuneF8,
+	20: RuneF9,
+	21: RuneF10,
+	23: RuneF11,
+	24: RuneF12,
+}
+
+// keyReader reads keys from a terminal, keeping the bytes read but not yet
+// used for the next key
+type keyReader struct {
+	r      io.Reader
+	unread []byte // bytes read but not yet used
+	keys   []Key  // keys put back, used before the unread bytes
+
+	// reading delivers the result of the read in progress, if any.
+	// There is never more than one read in progress, so no input is lost
+	// when a read is abandoned, e.g. on a timeout.
+	reading chan chunk
+
+	wipe bool // zero the read bytes when used, e.g. for secrets
+
+	// escDone is set when no more of a sequence started by an unread Esc
+	// is coming, e.g. after a timeout
+	escDone bool
+}
+
+// escTimeout is the time to wait for the rest of a sequence after Esc,
+// like ttimeoutlen in vim. Sequences may be split over reads, e.g. over ssh.
+const escTimeout = 100 * time.Millisecond
+
+// chunk is the result of a terminal read
+type chunk struct {
+	b   []byte
+	err error
+}
+
+// newKeyReader returns a keyReader reading from r
+func newKeyReader(r io.Reader) *keyReader {
+	return &keyReader{r: r}
+}
+
+// stdKeys is the keyReader of the standard terminal, shared by all Wyrms
+// using it so no read in progress is left for another
+var stdKeys = newKeyReader(stdTerm)
+
+// readCanceler is a terminal that can end a read in progress
+type readCanceler interface {
+	cancelRead()
+}
+
+// stopRead ends the read in progress, if the terminal can cancel reads,
+// so nothing is read from the terminal until the next read. Bytes already
+// read are kept. Otherwise the read is kept for the next reader.
+func (kr *keyReader) stopRead() {
+	c, ok := kr.r.(readCanceler)
+	if !ok || kr.reading == nil {
+		return
+	}
+
+	c.cancelRead()
+	kr.addRead(<-kr.reading)
+}
+
+// takeKey removes and returns the first unread key, if complete
+func (kr *keyReader) takeKey() (Key, bool) {
+	if len(kr.keys) > 0 {
+		k := kr.keys[0]
+		kr.keys = kr.keys[1:]
+		return k, true
+	}
+
+	k, n := decodeKey(kr.unread)
+	if n == 0 && kr.escDone && kr.escPending() {
+		k, n = flushKey(kr.unread)
+	}
+	kr.escDone = false
+	if n == 0 {
+		return 0, false
+	}
+
+	if kr.wipe {
+		zero(kr.unread[:n])
+	}
+	kr.unread = kr.unread[n:]
+	return k, true
+}
+
+// escPending returns true if the unread bytes start with an Esc that may
+// start a sequence
+func (kr *keyReader) escPending() bool {
+	if len(kr.keys) > 0 || len(kr.unread) == 0 || kr.unread[0] != byte(RuneEsc) {
+		return false
+	}
+	_, n := decodeKey(kr.unread)
+	return n == 0
+}
+
+// unreadKeys puts keys back, to be read before the unread bytes
+func (kr *keyReader) unreadKeys(ks []Key) {
+	kr.keys = append(append([]Key{}, ks...), kr.keys...)
+}
+
+// startRead starts a read, unless a read is already in progress.
+// Returns the channel delivering the result, that must be passed to addRead.
+func (kr *keyReader) startRead() <-chan chunk {
+	if kr.reading == nil {
+		ch := make(chan chunk, 1)
+		go func(r io.Reader) {
+			buf := make([]byte, 32)
+			n, err := r.Read(buf)
+			ch <- chunk{buf[:n], err}
+		}(kr.r)
+		kr.reading = ch
+	}
+
+	return kr.reading
+}
+
+// addRead adds the result of a read to the unread bytes.
+// Returns io.ErrUnexpectedEOF if input ends in the middle of a key.
+func (kr *keyReader) addRead(c chunk) error {
+	kr.reading = nil
+	if kr.wipe && len(kr.unread)+len(c.b) > cap(kr.unread) {
+		grown := make([]byte, len(kr.unread), 2*cap(kr.unread)+len(c.b))
+		copy(grown, kr.unread)
+		zero(kr.unread)
+		kr.unread = grown
+	}
+	kr.unread = append(kr.unread, c.b...)
+	if kr.wipe {
+		zero(c.b)
+	}
+
+	switch {
+	case c.err == errReadCanceled:
+		return nil
+	case c.err == io.EOF && len(c.b) > 0:
+		return nil // EOF is returned again by the next read
+	case c.err == io.EOF && kr.escPending():
+		kr.escDone = true
+		return nil // EOF is returned again by the next read
+	case c.err == io.EOF && len(kr.unread) > 0:
+		if kr.wipe {
+			zero(kr.unread)
+		}
+		kr.unread = nil
+		return io.ErrUnexpectedEOF
+	}
+	return c.err
+}
+
+// flushKeys moves the keys put back to the unread bytes, encoded as the
+// terminal sent them
+func (kr *keyReader) flushKeys() {
+	if len(kr.keys) == 0 {
+		return
+	}
+
+	b := []byte{}
+	for _, k := range kr.keys {
+		b = append(b, encodeKey(k)...)
+	}
+	kr.unread, kr.keys = append(b, kr.unread...), nil
+}
+
+// decodeKey decodes the first key in b and returns it and its length.
+// Returns length 0 if b is empty or the key is incomplete, also for an Esc
+// that may start a sequence.
+func decodeKey(b []byte) (Key, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+
+	if b[0] != byte(RuneEsc) {
+		if !utf8.FullRune(b) {
+			return 0, 0
+		}
+		return utf8.DecodeRune(b)
+	}
+
+	switch {
+	case len(b) == 1, len(b) == 2 && (b[1] == '[' || b[1] == 'O'):
+		return 0, 0 // maybe the start of a sequence, see flushKey
+	case b[1] == byte(RuneEsc):
+		return RuneEsc, 1
+	case b[1] == '[':
+		return decodeCSI(b)
+	case b[1] == 'O':
+		if k, ok := finalKeys[b[2]]; ok {
+			return k, 3
+		}
+		return utf8.RuneError, 3
+	}
+
+	// Alt and key
+	k, n := decodeKey(b[1:])
+	if n == 0 {
+		return 0, 0
+	}
+	return Alt(k), n + 1
+}
+
+// flushKey decodes the first key in b, starting with Esc, when no more
+// bytes of a sequence are coming: a lone Esc is RuneEsc, Esc [ and Esc O
+// are Alt keys and an unfinished control sequence is utf8.RuneError.
+func flushKey(b []byte) (Key, int) {
+	switch {
+	case len(b) == 1:
+		return RuneEsc, 1
+	case len(b) == 2 && (b[1] == '[' || b[1] == 'O'):
+		return Alt(rune(b[1])), 2
+	case b[1] == '[':
+		return utf8.RuneError, len(b)
+	}
+	return RuneEsc, 1
+}
+
+// encodeKey returns the bytes a terminal sends for k, the reverse of decodeKey
+func encodeKey(k Key) []byte {
+	if k&ModAlt != 0 {
+		return append([]byte{byte(RuneEsc)}, encodeKey(k&^ModAlt)...)
+	}
+
+	switch k {
+	case RuneF1, RuneF2, RuneF3, RuneF4:
+		return []byte{byte(RuneEsc), 'O', byte('P' + k - RuneF1)}
+	}
+	for c, fk := range finalKeys {
+		if fk == k {
+			return []byte{byte(RuneEsc), '[', c}
+		}
+	}
+	for n, tk := range tildeKeys {
+		if tk == k {
+			return []byte("\x1b[" + strconv.Itoa(n) + "~")
+		}
+	}
+
+	return []byte(string(k))
+}
+
+// decodeCSI decodes a control sequence, Esc [ parameters final byte.
+// Modifiers, like the 5 in Esc [ 1 ; 5 A, are ignored.
+func decodeCSI(b []byte) (Key, int) {
+	for i := 2; i < len(b); i++ {
+		c := b[i]
+		if c >= '0' && c <= '9' || c == ';' {
+			continue
+		}
+
+		if c == '~' {
+			param := string(b[2:i])
+			for j, p := range param {
+				if p == ';' {
+					param = param[:j]
+					break
+				}
+			}
+			n, _ := strconv.Atoi(param)
+			if k, ok := tildeKeys[n]; ok {
+				return k, i + 1
+			}
+			return utf8.RuneError, i + 1
+		}
+
+		if k, ok := finalKeys[c]; ok {
+			return k, i + 1
+		}
+		return utf8.RuneError, i + 1
+	}
+
+	return 0, 0
+}
@@ -0,0 +1,108 @@
+package wyrm
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecodeKey(t *testing.T) {
+	cases := []struct {
+		s string
+		k rune
+		n int
+	}{
+		{"a", 'a', 1},
+		{"ab", 'a', 1},
+		{"ä", 'ä', 2},
+		{"\xc3", 0, 0},
+		{"", 0, 0},
+		{"\x1b", 0, 0},
+		{"\x1b[A", RuneUp, 3},
+		{"\x1b[Bx", RuneDown, 3},
+		{"\x1b[1;5C", RuneRight, 6},
+		{"\x1bOD", RuneLeft, 3},
+		{"\x1bOP", RuneF1, 3},
+		{"\x1b[H", RuneHome, 3},
+		{"\x1b[4~", RuneEnd, 4},
+		{"\x1b[3~", RuneDelete, 4},
+		{"\x1b[5~", RunePgUp, 4},
+		{"\x1b[6;2~", RunePgDown, 6},
+		{"\x1b[15~", RuneF5, 5},
+		{"\x1b[24~", RuneF12, 5},
+		{"\x1b[99~", utf8.RuneError, 5},
+		{"\x1b[Z", utf8.RuneError, 3},
+		{"\x1b[1;", 0, 0},
+		{"\x1b[", 0, 0},
+		{"\x1bO", 0, 0},
+		{"\x1bf", Alt('f'), 2},
+		{"\x1bäx", Alt('ä'), 3},
+		{"\x1b\xc3", 0, 0},
+		{"\x1b\x1b", RuneEsc, 1},
+		{"\x18", Ctrl('x'), 1},
+	}
+
+	for _, c := range cases {
+		k, n := decodeKey([]byte(c.s))
+		if k != c.k || n != c.n {
+			t.Errorf("decodeKey(%q) = (%q, %d), expected (%q, %d)", c.s, k, n, c.k, c.n)
+		}
+	}
+}
+
+func TestFlushKey(t *testing.T) {
+	cases := []struct {
+		s string
+		k rune
+		n int
+	}{
+		{"\x1b", RuneEsc, 1},
+		{"\x1b[", Alt('['), 2},
+		{"\x1bO", Alt('O'), 2},
+		{"\x1b[1;", utf8.RuneError, 4},
+		{"\x1b\xc3", RuneEsc, 1},
+	}
+
+	for _, c := range cases {
+		k, n := flushKey([]byte(c.s))
+		if k != c.k || n != c.n {
+			t.Errorf("flushKey(%q) = (%q, %d), expected (%q, %d)", c.s, k, n, c.k, c.n)
+		}
+	}
+}
+
+func TestEncodeKey(t *testing.T) {
+	keys := []Key{'a', 'ä', RuneUp, RuneHome, RuneEnd, RuneF1, RuneF4, RuneF5, RuneDelete, RunePgDown,
+		Alt('x'), Alt('ä'), Ctrl('x')}
+
+	for _, k := range keys {
+		b := encodeKey(k)
+		if dk, n := decodeKey(b); dk != k || n != len(b) {
+			t.Errorf("decodeKey(encodeKey(%q)) = (%q, %d), expected (%q, %d)", k, dk, n, k, len(b))
+		}
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	cases := []struct {
+		k    Key
+		name string
+	}{
+		{'a', "a"},
+		{'å', "å"},
+		{RuneUp, "up"},
+		{RuneF12, "f12"},
+		{RuneSpace, "space"},
+		{Ctrl('x'), "C-x"},
+		{Ctrl('X'), "C-x"},
+		{Ctrl('l'), "C-l"},
+		{Alt('f'), "M-f"},
+		{Alt(Ctrl('x')), "M-C-x"},
+		{Alt(RuneLeft), "M-left"},
+	}
+
+	for _, c := range cases {
+		if name := KeyName(c.k); name != c.name {
+			t.Errorf("KeyName(%q) = %q, expected %q", c.k, name, c.name)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package wyrm command tree loading
+package wyrm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Registry maps names to functions, used to resolve the functions of loaded
+// commands. The functions are func() error or func(*Context) error.
+type Registry map[string]interface{}
+
+// Unmarshal decodes data into v, e.g. json.Unmarshal or yaml.Unmarshal
+type Unmarshal func(data []byte, v interface{}) error
+
+// CommandSpec is a command as described in a file
+type CommandSpec struct {
+	Key         string        `json:"key" yaml:"key" toml:"key"` // e.g. "a", "C-x", "up" or chord "gg"
+	Title       string        `json:"title" yaml:"title" toml:"title"`
+	Description string        `json:"description" yaml:"description" toml:"description"`
+	Sort        int           `json:"sort" yaml:"sort" toml:"sort"`
+	Repeat      bool          `json:"repeat" yaml:"repeat" toml:"repeat"`
+	Function    string        `json:"function" yaml:"function" toml:"function"` // names in the Registry
+	Pre         string        `json:"pre" yaml:"pre" toml:"pre"`
+	Post        string        `json:"post" yaml:"post" toml:"post"`
+	Shell       string        `json:"shell" yaml:"shell" toml:"shell"` // see ShellCmd
+	Confirm     string        `json:"confirm" yaml:"confirm" toml:"confirm"`
+	Commands    []CommandSpec `json:"commands" yaml:"commands" toml:"commands"`
+}
+
+// LoadCommandsFile builds a command tree from a JSON file.
+// Other formats can be loaded with LoadCommands and a matching Unmarshal.
+func LoadCommandsFile(path string, reg Registry) (*Command, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadCommands(data, json.Unmarshal, reg)
+}
+
+// LoadCommands builds a command tree from data decoded by unmarshal.
+// The top level command is the root, its key is ignored.
+// Unknown function names and keys are reported as errors.
+func LoadCommands(data []byte, unmarshal Unmarshal, reg Registry) (*Command, error) {
+	spec := CommandSpec{}
+	if err := unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	return spec.build(spec.Title, reg)
+}
+
+// build returns the command of the spec, path is used in error messages
+func (s CommandSpec) build(path string, reg Registry) (*Command, error) {
+	cmd := &Command{
+		Title:       s.Title,
+		Description: s.Description,
+		Sort:        s.Sort,
+		Repeat:      s.Repeat,
+		Shell:       s.Shell,
+		Confirm:     s.Confirm,
+	}
+
+	if _, err := parseShell(s.Shell); err != nil {
+		return nil, fmt.Errorf("%s: shell: %w", path, err)
+	}
+
+	var err error
+	if cmd.Function, cmd.FunctionCtx, err = reg.lookup(s.Function); err != nil {
+		return nil, fmt.Errorf("%s: function: %w", path, err)
+	}
+	if cmd.Pre, cmd.PreCtx, err = reg.lookup(s.Pre); err != nil {
+		return nil, fmt.Errorf("%s: pre: %w", path, err)
+	}
+	if cmd.Post, cmd.PostCtx, err = reg.lookup(s.Post); err != nil {
+		return nil, fmt.Errorf("%s: post: %w", path, err)
+	}
+
+	for _, sub := range s.Commands {
+		subPath := path + "/" + sub.Key
+		c, err := sub.build(subPath, reg)
+		if err != nil {
+			return nil, err
+		}
+
+		k, err := ParseKey(sub.Key)
+		if err == ErrUnknownKey && utf8.RuneCountInString(sub.Key) > 1 {
+			// Not a key, a chord
+			if _, exists := cmd.Chords[sub.Key]; exists {
+				return nil, fmt.Errorf("%s: %w", subPath, ErrDuplicateKey)
+			}
+			if cmd.Chords == nil {
+				cmd.Chords = map[string]*Command{}
+			}
+			cmd.Chords[sub.Key] = c
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", subPath, err)
+		}
+
+		if _, exists := cmd.Commands[k]; exists {
+			return nil, fmt.Errorf("%s: %w", subPath, ErrDuplicateKey)
+		}
+		if cmd.Commands == nil {
+			cmd.Commands = map[Key]*Command{}
+		}
+		cmd.Commands[k] = c
+	}
+
+	return cmd, nil
+}
+
+// lookup returns the function named name, as one of the two kinds.
+// An empty name is no function.
+func (r Registry) lookup(name string) (func() error, func(*Context) error, error) {
+	if name == "" {
+		return nil, nil, nil
+	}
+
+	switch f := r[name].(type) {
+	case func() error:
+		return f, nil, nil
+	case func(*Context) error:
+		return nil, f, nil
+	}
+
+	return nil, nil, fmt.Errorf("%w %q", ErrUnknownFunction, name)
+}
+
+// ParseKey returns the key for a name as returned by KeyName, e.g.
+// "a", "space", "up", "C-x" or "M-f"
+func ParseKey(s string) (Key, error) {
+	if strings.HasPrefix(s, "M-") && len(s) > 2 {
+		k, err := ParseKey(s[2:])
+		if err != nil {
+			return 0, err
+		}
+		return Alt(k), nil
+	}
+	if strings.HasPrefix(s, "C-") && utf8.RuneCountInString(s) == 3 {
+		return Ctrl([]rune(s)[2]), nil
+	}
+
+	if utf8.RuneCountInString(s) == 1 {
+		return []rune(s)[0], nil
+	}
+
+	for k, info := range globalKeyInfo {
+		if info[0] == s {
+			return k, nil
+		}
+	}
+	for k, name := range keyNames {
+		if name == s {
+			return k, nil
+		}
+	}
+
+	return 0, ErrUnknownKey
+}
@@ -0,0 +1,102 @@
+package wyrm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const testCommandsJSON = `{
+	"title": "root",
+	"commands": [
+		{"key": "h", "title": "hello", "sort": 1, "function": "hello", "post": "ctx"},
+		{"key": "C-x", "title": "ctrl"},
+		{"key": "up", "title": "up"},
+		{"key": "gg", "title": "top", "function": "ctx", "confirm": "sure"},
+		{"key": "i", "title": "input", "commands": [
+			{"key": "s", "title": "string", "function": "hello"}
+		]}
+	]
+}`
+
+func TestLoadCommands(t *testing.T) {
+	reg := Registry{
+		"hello": func() error { return nil },
+		"ctx":   func(*Context) error { return nil },
+	}
+
+	root, err := LoadCommands([]byte(testCommandsJSON), json.Unmarshal, reg)
+	if err != nil {
+		t.Fatalf("LoadCommands error %q", err)
+	}
+
+	h := root.Commands['h']
+	if h == nil || h.Title != "hello" || h.Sort != 1 || h.Function == nil || h.PostCtx == nil {
+		t.Errorf("LoadCommands h = %+v, expected hello with function and post", h)
+	}
+	if c := root.Commands[Ctrl('x')]; c == nil || c.Title != "ctrl" {
+		t.Errorf("LoadCommands C-x = %+v, expected ctrl", c)
+	}
+	if c := root.Commands[RuneUp]; c == nil || c.Title != "up" {
+		t.Errorf("LoadCommands up = %+v, expected up", c)
+	}
+	if c := root.Chords["gg"]; c == nil || c.FunctionCtx == nil || c.Confirm != "sure" {
+		t.Errorf("LoadCommands gg = %+v, expected chord with function and confirm", c)
+	}
+	if c := root.Commands['i'].Commands['s']; c == nil || c.Title != "string" {
+		t.Errorf("LoadCommands i s = %+v, expected string", c)
+	}
+}
+
+func TestLoadCommandsErrors(t *testing.T) {
+	cases := []struct {
+		json string
+		err  error
+	}{
+		{`{"commands": [{"key": "a", "function": "missing"}]}`, ErrUnknownFunction},
+		{`{"commands": [{"key": "a", "commands": [{"key": "b", "pre": "missing"}]}]}`, ErrUnknownFunction},
+		{`{"commands": [{"key": ""}]}`, ErrUnknownKey},
+		{`{"commands": [{"key": "a"}, {"key": "a"}]}`, ErrDuplicateKey},
+		{`{"commands": [{"key": "space"}, {"key": " "}]}`, ErrDuplicateKey},
+	}
+
+	for _, c := range cases {
+		_, err := LoadCommands([]byte(c.json), json.Unmarshal, Registry{})
+		if !errors.Is(err, c.err) {
+			t.Errorf("LoadCommands(%s) error %q, expected %q", c.json, err, c.err)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	cases := []struct {
+		s   string
+		k   Key
+		err error
+	}{
+		{"a", 'a', nil},
+		{"ö", 'ö', nil},
+		{"space", RuneSpace, nil},
+		{"f12", RuneF12, nil},
+		{"C-x", Ctrl('x'), nil},
+		{"M-f", Alt('f'), nil},
+		{"M-C-x", Alt(Ctrl('x')), nil},
+		{"M-up", Alt(RuneUp), nil},
+		{"gg", 0, ErrUnknownKey},
+		{"M-gg", 0, ErrUnknownKey},
+	}
+
+	for _, c := range cases {
+		k, err := ParseKey(c.s)
+		if err != c.err {
+			t.Errorf("ParseKey(%q) error %q, expected %q", c.s, err, c.err)
+			continue
+		}
+		if k != c.k {
+			t.Errorf("ParseKey(%q) = %q, expected %q", c.s, k, c.k)
+		}
+		if err == nil && KeyName(k) != c.s {
+			t.Errorf("KeyName(ParseKey(%q)) = %q", c.s, KeyName(k))
+		}
+	}
+}
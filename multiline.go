@@ -0,0 +1,137 @@
+// Package wyrm multi-line text input
+package wyrm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+// Keys used in InputMultiline
+const (
+	runeEditor = '\x18' // C-x opens the text in an editor
+	runeCtrlM  = '\r'   // ends a line in the line editor
+)
+
+// InputMultiline prints prompt and reads lines of text from user, starting
+// with the lines of def. A line with only "." or C-d on an empty line ends
+// the input. C-x opens the text in $VISUAL or $EDITOR, with the terminal
+// attached, and returns the saved text.
+// Returns ErrEmpty for no text or ErrAbort on C-c.
+func InputMultiline(p, def string) (string, error) {
+	return current().InputMultiline(p, def)
+}
+
+// InputMultiline is InputMultiline on the terminal of w
+func (w *Wyrm) InputMultiline(p, def string) (string, error) {
+	editing := false
+	r, err := w.newLineEditor(HistoryNone, editorKeyReader{lineReader{w}, &editing})
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	r.SetPrompt("")
+
+	fmt.Fprintln(w.term, p)
+	lines := []string{}
+	if def != "" {
+		lines = strings.Split(def, "\n")
+		fmt.Fprintln(w.term, def)
+	}
+
+	for {
+		line, err := r.Readline()
+		switch {
+		case err == readline.ErrInterrupt:
+			w.cancelCommand()
+			return "", ErrAbort
+		case err == io.EOF && w.hasSignaled():
+			return "", ErrSignal
+		case err == io.EOF:
+			return joinLines(lines)
+		case err != nil:
+			return "", err
+		case editing:
+			if line != "" {
+				lines = append(lines, line)
+			}
+			return w.editText(strings.Join(lines, "\n"))
+		case line == ".":
+			return joinLines(lines)
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+// editorKeyReader reads like lineReader, with the editor key read as the end
+// of the line, so the line editor stops reading, and editing set
+type editorKeyReader struct {
+	lineReader
+	editing *bool
+}
+
+// Read reads at most one byte into p
+func (e editorKeyReader) Read(p []byte) (int, error) {
+	n, err := e.lineReader.Read(p)
+	if n == 1 && p[0] == runeEditor {
+		*e.editing = true
+		p[0] = runeCtrlM
+	}
+	return n, err
+}
+
+// joinLines returns the lines as text, or ErrEmpty if there are none
+func joinLines(lines []string) (string, error) {
+	if len(lines) == 0 {
+		return "", ErrEmpty
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// editText opens text in $VISUAL or $EDITOR, or vi, and returns the saved text
+func (w *Wyrm) editText(text string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := os.CreateTemp("", "wyrm-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(text + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The editor can be a command line, e.g. "code --wait"
+	if err := w.runShell(context.Background(), "/bin/sh", editor+" "+shellQuote(f.Name())); err != nil {
+		return "", err
+	}
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+
+	text = strings.TrimSuffix(string(b), "\n")
+	if text == "" {
+		return "", ErrEmpty
+	}
+
+	return text, nil
+}
@@ -0,0 +1,133 @@
+// Package wyrm number input
+package wyrm
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Multipliers of number suffixes
+var numberSuffixes = map[string]float64{
+	"k": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+}
+
+// Thousands separators accepted in numbers, one kind per number
+const numberSeparators = ", _"
+
+// InputIntRange read an integer in the range min to max from the user.
+// Thousands separators, e.g. 1,000 or 1 000, and suffixes k, M and G are
+// accepted.
+func InputIntRange(p, def string, min, max int) (int, error) {
+	return current().InputIntRange(p, def, min, max)
+}
+
+// InputIntRange is InputIntRange on the terminal of w
+func (w *Wyrm) InputIntRange(p, def string, min, max int) (int, error) {
+	return InputOn(w, p, def, TextOptions{History: HistoryNumber}, parseInt, InRange(min, max))
+}
+
+// InputFloat read a decimal number in the range min to max from the user.
+// Thousands separators, e.g. 1,000.5, and suffixes k, M and G are accepted.
+func InputFloat(p, def string, min, max float64) (float64, error) {
+	return current().InputFloat(p, def, min, max)
+}
+
+// InputFloat is InputFloat on the terminal of w
+func (w *Wyrm) InputFloat(p, def string, min, max float64) (float64, error) {
+	return InputOn(w, p, def, TextOptions{History: HistoryNumber}, parseFloat, InRange(min, max))
+}
+
+// parseInt parses an integer with optional separators and suffix
+func parseInt(s string) (int, error) {
+	s, err := removeSeparators(s)
+	if err != nil {
+		return 0, err
+	}
+
+	s, mult := splitSuffix(s)
+	if mult == 1 {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, ErrNoNumber
+		}
+		return i, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	f *= mult
+	if err != nil || f != math.Trunc(f) || f < math.MinInt || f >= -math.MinInt {
+		return 0, ErrNoNumber
+	}
+
+	return int(f), nil
+}
+
+// parseFloat parses a decimal number with optional separators and suffix
+func parseFloat(s string) (float64, error) {
+	s, err := removeSeparators(s)
+	if err != nil {
+		return 0, err
+	}
+
+	s, mult := splitSuffix(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, ErrNoNumber
+	}
+
+	return f * mult, nil
+}
+
+// removeSeparators removes thousands separators from the integer part of s.
+// Returns ErrNoNumber unless they separate groups of three digits, e.g. to
+// not take 1,5 as 15.
+func removeSeparators(s string) (string, error) {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && !strings.ContainsRune(numberSeparators, r)
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	whole, rest := s[:i], s[i:]
+
+	if strings.ContainsAny(rest, numberSeparators) {
+		return "", ErrNoNumber
+	}
+
+	j := strings.IndexAny(whole, numberSeparators)
+	if j < 0 {
+		return sign + s, nil
+	}
+
+	groups := strings.Split(whole, whole[j:j+1])
+	for i, g := range groups {
+		valid := len(g) == 3
+		if i == 0 {
+			valid = len(g) >= 1 && len(g) <= 3
+		}
+		if !valid || strings.ContainsAny(g, numberSeparators) {
+			return "", ErrNoNumber
+		}
+	}
+
+	return sign + strings.Join(groups, "") + rest, nil
+}
+
+// splitSuffix returns s without a number suffix and its multiplier
+func splitSuffix(s string) (string, float64) {
+	for suffix, mult := range numberSuffixes {
+		if strings.HasSuffix(s, suffix) {
+			return strings.TrimSuffix(s, suffix), mult
+		}
+	}
+
+	return s, 1
+}
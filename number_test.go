@@ -0,0 +1,93 @@
+package wyrm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseInt(t *testing.T) {
+	cases := []struct {
+		s   string
+		i   int
+		err error
+	}{
+		{"42", 42, nil},
+		{"-42", -42, nil},
+		{"1,000", 1000, nil},
+		{"1 000 000", 1000000, nil},
+		{"10_000", 10000, nil},
+		{"5k", 5000, nil},
+		{"1.5k", 1500, nil},
+		{"-2M", -2000000, nil},
+		{"1.5", 0, ErrNoNumber},
+		{"1.2345k", 0, ErrNoNumber},
+		{"10000000000G", 0, ErrNoNumber},
+		{"k", 0, ErrNoNumber},
+		{"1,5", 0, ErrNoNumber},
+		{"12,34", 0, ErrNoNumber},
+		{"1,2,3", 0, ErrNoNumber},
+		{",000", 0, ErrNoNumber},
+		{"1000,000", 0, ErrNoNumber},
+		{"1,000_000", 0, ErrNoNumber},
+		{"-1,000", -1000, nil},
+		{"1,000k", 1000000, nil},
+		{"ten", 0, ErrNoNumber},
+	}
+
+	for _, c := range cases {
+		i, err := parseInt(c.s)
+		if err != c.err {
+			t.Errorf("parseInt(%q) error %q, expected %q", c.s, err, c.err)
+			continue
+		}
+		if i != c.i {
+			t.Errorf("parseInt(%q) = %d, expected %d", c.s, i, c.i)
+		}
+	}
+}
+
+func TestParseFloat(t *testing.T) {
+	cases := []struct {
+		s   string
+		f   float64
+		err error
+	}{
+		{"2.5", 2.5, nil},
+		{"-0.25", -0.25, nil},
+		{"1,234.5", 1234.5, nil},
+		{"2.5k", 2500, nil},
+		{"1.5M", 1500000, nil},
+		{"inf", 0, ErrNoNumber},
+		{"1,5", 0, ErrNoNumber},
+		{"12,34", 0, ErrNoNumber},
+		{"1.000,5", 0, ErrNoNumber},
+		{"1,000.000,5", 0, ErrNoNumber},
+		{"1.2.3", 0, ErrNoNumber},
+	}
+
+	for _, c := range cases {
+		f, err := parseFloat(c.s)
+		if err != c.err {
+			t.Errorf("parseFloat(%q) error %q, expected %q", c.s, err, c.err)
+			continue
+		}
+		if f != c.f {
+			t.Errorf("parseFloat(%q) = %v, expected %v", c.s, f, c.f)
+		}
+	}
+}
+
+func TestRangeError(t *testing.T) {
+	err := InRange(-10, 10)(11)
+
+	var re *RangeError[int]
+	if !errors.As(err, &re) || re.Min != -10 || re.Max != 10 || re.Value != 11 {
+		t.Errorf("InRange(-10, 10)(11) = %#v, expected RangeError with bounds", err)
+	}
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("InRange(-10, 10)(11) = %q, expected to match ErrOutOfRange", err)
+	}
+	if msg := err.Error(); msg != "11 is out of range -10 to 10" {
+		t.Errorf("RangeError.Error() = %q, expected %q", msg, "11 is out of range -10 to 10")
+	}
+}
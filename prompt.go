@@ -3,6 +3,7 @@ package wyrm
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -21,6 +22,9 @@ func (w *Wyrm) CommandPrompt() string {
 
 	d := w.state.cmd.Title
 	k := "[" + strings.Join(w.GetCurrentKeyStrings(), "") + "] "
+	if w.state.count > 0 {
+		k += strconv.Itoa(w.state.count)
+	}
 	return fmt.Sprintf("%s %s$ ", d, k)
 }
 
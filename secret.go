@@ -0,0 +1,98 @@
+// Package wyrm secret input
+package wyrm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// runeInterrupt is Ctrl-C, read as a key if the terminal doesn't signal it
+const runeInterrupt = '\x03'
+
+// InputSecret prints prompt and reads a secret, e.g. a password, from user.
+// Each character is shown as '*' and is never added to history.
+// Backspace removes the last character and C-u all of them.
+// Returns ErrAbort on Esc or C-c, also when the terminal sends SIGINT.
+// The caller should zero the returned bytes when done with them, the read
+// bytes are zeroed when used.
+func InputSecret(p string) ([]byte, error) {
+	return current().InputSecret(p)
+}
+
+// InputSecret is InputSecret on the terminal of w
+func (w *Wyrm) InputSecret(p string) ([]byte, error) {
+	fmt.Fprint(w.term, p)
+
+	intr, stop := w.watchInterrupt()
+	defer stop()
+	w.keys.wipe = true
+	defer func() { w.keys.wipe = false }()
+
+	secret := []byte{}
+	for {
+		k, err := w.waitKey(context.Background(), 0, nil, intr)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			zero(secret)
+			fmt.Fprintln(w.term, "")
+			return nil, ErrDone
+		}
+		if err != nil && err != ErrAbort {
+			zero(secret)
+			return nil, err
+		}
+
+		switch {
+		case k == RuneEnter:
+			fmt.Fprintln(w.term, "")
+			if len(secret) == 0 {
+				return nil, ErrEmpty
+			}
+			return secret, nil
+		case err == ErrAbort || k == RuneEsc || k == runeInterrupt:
+			zero(secret)
+			fmt.Fprintln(w.term, "")
+			w.cancelCommand()
+			return nil, ErrAbort
+		case k == runeBackspace || k == runeCtrlH:
+			if len(secret) > 0 {
+				_, n := utf8.DecodeLastRune(secret)
+				zero(secret[len(secret)-n:])
+				secret = secret[:len(secret)-n]
+				fmt.Fprint(w.term, "\b \b")
+			}
+		case k == Ctrl('u'):
+			n := utf8.RuneCount(secret)
+			zero(secret)
+			secret = secret[:0]
+			fmt.Fprint(w.term, strings.Repeat("\b \b", n))
+		case unicode.IsPrint(k):
+			secret = appendSecret(secret, k)
+			fmt.Fprint(w.term, "*")
+		}
+	}
+}
+
+// appendSecret appends the UTF-8 encoding of r to secret, zeroing the old
+// bytes if they have to be moved
+func appendSecret(secret []byte, r rune) []byte {
+	n := utf8.RuneLen(r)
+	if len(secret)+n > cap(secret) {
+		grown := make([]byte, len(secret), 2*cap(secret)+n)
+		copy(grown, secret)
+		zero(secret)
+		secret = grown
+	}
+
+	return utf8.AppendRune(secret, r)
+}
+
+// zero overwrites b with zeroes
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,37 @@
+package wyrm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAppendSecret(t *testing.T) {
+	old := make([]byte, 0, 2)
+	secret := appendSecret(old, 'a')
+	secret = appendSecret(secret, 'b')
+	secret = appendSecret(secret, 'ö')
+
+	if string(secret) != "abö" {
+		t.Errorf("appendSecret = %q, expected %q", secret, "abö")
+	}
+	if !bytes.Equal(old[:2], []byte{0, 0}) {
+		t.Errorf("appendSecret left %q, expected the old bytes zeroed", old[:2])
+	}
+}
+
+func TestWipeReads(t *testing.T) {
+	kr := newKeyReader(nil)
+	kr.wipe = true
+
+	b := []byte("pw")
+	if err := kr.addRead(chunk{b, nil}); err != nil {
+		t.Fatalf("addRead error %q", err)
+	}
+	backing := kr.unread
+	kr.takeKey()
+	kr.takeKey()
+
+	if !bytes.Equal(b, []byte{0, 0}) || !bytes.Equal(backing, []byte{0, 0}) {
+		t.Errorf("read bytes = %q and %q, expected them zeroed", b, backing)
+	}
+}
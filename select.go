@@ -0,0 +1,345 @@
+// Package wyrm selection from a list of items
+package wyrm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SelectRows is the number of items InputSelect shows at a time
+var SelectRows = 10
+
+// Keys used when editing the InputSelect filter
+const (
+	runeBackspace = '\x7f'
+	runeCtrlH     = '\b'
+)
+
+// InputSelect shows the items and reads a selection from the user.
+// Typing narrows the list by fuzzy match, up and down (or C-p and C-n)
+// move the cursor and Enter picks. If all items fit the index runes, they
+// are shown with their index rune and M-<index rune> picks directly.
+// Returns the index and the item selected, ErrEmpty if there are no items
+// or ErrAbort on Esc or SIGINT.
+func InputSelect(p string, items []string) (int, string, error) {
+	return current().InputSelect(p, items)
+}
+
+// InputSelect is InputSelect on the terminal of w
+func (w *Wyrm) InputSelect(p string, items []string) (int, string, error) {
+	if len(items) == 0 {
+		return -1, "", ErrEmpty
+	}
+
+	intr, stop := w.watchInterrupt()
+	defer stop()
+
+	indexed := len(items) <= len(GetIndexRunes())
+	filter := []rune{}
+	matches := fuzzyFilter("", items)
+	cursor, top, drawn := 0, 0, 0
+
+	for {
+		// Keep the cursor within the visible rows
+		if cursor < top {
+			top = cursor
+		}
+		if cursor >= top+SelectRows {
+			top = cursor - SelectRows + 1
+		}
+
+		drawn = w.drawSelect(drawn, p, string(filter), items, matches, cursor, top, indexed)
+
+		k, err := w.waitKey(context.Background(), 0, nil, intr)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			fmt.Fprintln(w.term, "")
+			return -1, "", ErrDone
+		}
+		if err != nil && err != ErrAbort {
+			fmt.Fprintln(w.term, "")
+			return -1, "", err
+		}
+
+		switch {
+		case err == ErrAbort || k == RuneEsc:
+			fmt.Fprintln(w.term, "")
+			w.cancelCommand()
+			return -1, "", ErrAbort
+		case k == RuneEnter:
+			if len(matches) == 0 {
+				continue
+			}
+			fmt.Fprintln(w.term, "")
+			return matches[cursor], items[matches[cursor]], nil
+		case k == RuneUp || k == Ctrl('p'):
+			if cursor > 0 {
+				cursor--
+			}
+		case k == RuneDown || k == Ctrl('n'):
+			if cursor < len(matches)-1 {
+				cursor++
+			}
+		case k == runeBackspace || k == runeCtrlH:
+			if len(filter) > 0 {
+				filter = filter[:len(filter)-1]
+				matches, cursor = fuzzyFilter(string(filter), items), 0
+			}
+		case k&ModAlt != 0 && indexed:
+			i, err := GetRuneIndex(k &^ ModAlt)
+			if err == nil && i < len(items) {
+				fmt.Fprintln(w.term, "")
+				return i, items[i], nil
+			}
+		case unicode.IsPrint(k):
+			filter = append(filter, k)
+			matches, cursor = fuzzyFilter(string(filter), items), 0
+		}
+	}
+}
+
+// drawSelect draws the visible matches and the prompt with the filter,
+// replacing the drawn number of lines from the previous draw on
+// interactive terminals. Returns the number of lines drawn.
+func (w *Wyrm) drawSelect(drawn int, p, filter string, items []string, matches []int, cursor, top int, indexed bool) int {
+	if w.term.IsTerminal() {
+		if drawn > 0 {
+			fmt.Fprintf(w.term, "\x1b[%dA", drawn)
+		}
+		fmt.Fprint(w.term, "\r\x1b[J")
+	} else if drawn > 0 {
+		fmt.Fprintln(w.term, "")
+	}
+
+	lines := 0
+	for i := top; i < len(matches) && i < top+SelectRows; i++ {
+		marker := "  "
+		if i == cursor {
+			marker = "> "
+		}
+		if indexed {
+			marker += string(indices[matches[i]]) + ": "
+		}
+		fmt.Fprintln(w.term, marker+items[matches[i]])
+		lines++
+	}
+	fmt.Fprint(w.term, p+filter)
+
+	return lines
+}
+
+// fuzzyFilter returns the indexes of the items matching pattern, best
+// first, and shorter items first on equal scores.
+// All items are returned in order for an empty pattern.
+func fuzzyFilter(pattern string, items []string) []int {
+	matches := []int{}
+	if pattern == "" {
+		for i := range items {
+			matches = append(matches, i)
+		}
+		return matches
+	}
+
+	scores := map[int]int{}
+	for i, item := range items {
+		if score, ok := fuzzyMatch(pattern, item); ok {
+			matches = append(matches, i)
+			scores[i] = score
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		ia, ib := matches[a], matches[b]
+		if scores[ia] != scores[ib] {
+			return scores[ia] > scores[ib]
+		}
+		return len(items[ia]) < len(items[ib])
+	})
+
+	return matches
+}
+
+// fuzzyMatch reports if the runes of pattern appear in order in s,
+// ignoring case, and scores the match. Consecutive runes and runes at
+// the start of words score higher.
+func fuzzyMatch(pattern, s string) (int, bool) {
+	ps := []rune(strings.ToLower(pattern))
+	rs := []rune(strings.ToLower(s))
+
+	score, pi, prev := 0, 0, -2
+	for i := 0; i < len(rs) && pi < len(ps); i++ {
+		if rs[i] != ps[pi] {
+			continue
+		}
+
+		score++
+		if i == prev+1 {
+			score += 2
+		}
+		if i == 0 || !unicode.IsLetter(rs[i-1]) && !unicode.IsDigit(rs[i-1]) {
+			score += 3
+		}
+		prev = i
+		pi++
+	}
+
+	if pi < len(ps) {
+		return 0, false
+	}
+
+	return score, true
+}
+
+// Keys used to change page in InputIndexed, besides the left and right keys
+const (
+	runePrevPage = '<'
+	runeNextPage = '>'
+)
+
+// InputIndexed shows the items with their index runes and reads a key
+// selecting one. If there are more items than index runes, they are shown
+// a page at a time, changed with < and > (or left and right, pgup and
+// pgdown) unless those are index runes.
+// Returns the index selected, ErrEmpty if there are no items or ErrAbort
+// on Esc.
+func InputIndexed(p string, items []string) (int, error) {
+	return current().InputIndexed(p, items)
+}
+
+// InputIndexed is InputIndexed on the terminal of w
+func (w *Wyrm) InputIndexed(p string, items []string) (int, error) {
+	if len(items) == 0 {
+		return -1, ErrEmpty
+	}
+
+	page := 0
+
+	for {
+		from, to, pages := indexPage(len(items), page)
+
+		for i := from; i < to; i++ {
+			fmt.Fprintf(w.term, "  %c: %s\n", indices[i-from], items[i])
+		}
+		w.printPage(page, pages)
+
+		for changed := false; !changed; {
+			k, err := w.InputRune(p)
+			if err != nil {
+				return -1, err
+			}
+
+			if i, err := GetRuneIndex(k); err == nil && from+i < to {
+				return from + i, nil
+			}
+
+			page, changed = changePage(k, page, pages)
+		}
+	}
+}
+
+// indexPage returns the range of the n items on page, from and to
+// exclusive, and the number of pages when paging by the index runes
+func indexPage(n, page int) (int, int, int) {
+	size := len(GetIndexRunes())
+	from := page * size
+	to := from + size
+	if to > n {
+		to = n
+	}
+
+	return from, to, (n + size - 1) / size
+}
+
+// printPage prints the page number if there is more than one page
+func (w *Wyrm) printPage(page, pages int) {
+	if pages > 1 {
+		fmt.Fprintf(w.term, "  (page %d/%d, %c %c for more)\n", page+1, pages, runePrevPage, runeNextPage)
+	}
+}
+
+// changePage returns the page k changes to and true, or page and false
+// if k doesn't change page
+func changePage(k Key, page, pages int) (int, bool) {
+	switch k {
+	case runePrevPage, RuneLeft, RunePgUp:
+		if page > 0 {
+			return page - 1, true
+		}
+	case runeNextPage, RuneRight, RunePgDown:
+		if page < pages-1 {
+			return page + 1, true
+		}
+	}
+
+	return page, false
+}
+
+// Keys used to select all or no items in InputMultiSelect
+const (
+	runeSelectAll  = '+'
+	runeSelectNone = '-'
+)
+
+// InputMultiSelect shows the items with their index runes and checkboxes,
+// marked for the preselected indexes, and reads keys toggling them.
+// + selects all and - none, unless those are index runes. Pages are
+// changed like in InputIndexed.
+// Returns the selected indexes in order on Enter or ErrAbort on Esc.
+func InputMultiSelect(p string, items []string, preselected []int) ([]int, error) {
+	return current().InputMultiSelect(p, items, preselected)
+}
+
+// InputMultiSelect is InputMultiSelect on the terminal of w
+func (w *Wyrm) InputMultiSelect(p string, items []string, preselected []int) ([]int, error) {
+	selected := make([]bool, len(items))
+	for _, i := range preselected {
+		if i >= 0 && i < len(items) {
+			selected[i] = true
+		}
+	}
+
+	page := 0
+
+	for {
+		from, to, pages := indexPage(len(items), page)
+
+		for i := from; i < to; i++ {
+			box := "[ ]"
+			if selected[i] {
+				box = "[x]"
+			}
+			fmt.Fprintf(w.term, "  %c: %s %s\n", indices[i-from], box, items[i])
+		}
+		w.printPage(page, pages)
+
+		k, err := w.InputRune(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if i, err := GetRuneIndex(k); err == nil && from+i < to {
+			selected[from+i] = !selected[from+i]
+			continue
+		}
+
+		switch k {
+		case RuneEnter:
+			chosen := []int{}
+			for i, s := range selected {
+				if s {
+					chosen = append(chosen, i)
+				}
+			}
+			return chosen, nil
+		case runeSelectAll, runeSelectNone:
+			for i := range selected {
+				selected[i] = k == runeSelectAll
+			}
+		default:
+			page, _ = changePage(k, page, pages)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package wyrm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		s       string
+		ok      bool
+	}{
+		{"", "anything", true},
+		{"abc", "abc", true},
+		{"abc", "a big cat", true},
+		{"ABC", "abc", true},
+		{"åö", "Åsa Öberg", true},
+		{"acb", "abc", false},
+		{"abcd", "abc", false},
+		{"x", "", false},
+	}
+
+	for _, c := range cases {
+		if _, ok := fuzzyMatch(c.pattern, c.s); ok != c.ok {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, expected %v", c.pattern, c.s, ok, c.ok)
+		}
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	items := []string{"remove tag", "rename tag", "list tags", "new entry", "tag"}
+
+	cases := []struct {
+		pattern  string
+		expected []int
+	}{
+		{"", []int{0, 1, 2, 3, 4}},
+		{"tag", []int{4, 0, 1, 2}},
+		{"ren", []int{1}},
+		{"re", []int{0, 1}},
+		{"rt", []int{0, 1}},
+		{"zz", []int{}},
+	}
+
+	for _, c := range cases {
+		if got := fuzzyFilter(c.pattern, items); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("fuzzyFilter(%q) = %v, expected %v", c.pattern, got, c.expected)
+		}
+	}
+}
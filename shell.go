@@ -0,0 +1,114 @@
+// Package wyrm shell command execution
+package wyrm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+)
+
+// ShellCmd returns a command function running line with /bin/sh.
+// Output is written to the terminal as it comes and a non-zero exit status
+// is returned as an error.
+//
+// The line is a text/template where {{input "name"}} prompts for a value
+// and {{var "name"}} is a value from an earlier prompt or set with SetVar.
+// Values are quoted for the shell, e.g. git commit -m {{input "message"}}.
+func ShellCmd(line string) func(*Context) error {
+	t, err := parseShell(line)
+
+	return func(ctx *Context) error {
+		if err != nil {
+			return err
+		}
+
+		w := ctx.Wyrm
+		b := strings.Builder{}
+		err := t.Funcs(template.FuncMap{
+			"input": func(name string) (string, error) {
+				v, err := w.InputText(w.InputPrompt(name), w.GetVar(name))
+				if err != nil {
+					return "", err
+				}
+				w.SetVar(name, v)
+				return shellQuote(v), nil
+			},
+			"var": func(name string) string {
+				return shellQuote(w.GetVar(name))
+			},
+		}).Execute(&b, nil)
+		if err != nil {
+			return inputError(err)
+		}
+
+		return w.runShell(ctx, "/bin/sh", b.String())
+	}
+}
+
+// inputError returns the error of a prompt in a shell command template
+// unwrapped, so Run handles it as from other commands. No input, empty or
+// by Ctrl-D, aborts the command.
+func inputError(err error) error {
+	switch {
+	case errors.Is(err, ErrEmpty), errors.Is(err, ErrDone), errors.Is(err, ErrAbort):
+		return ErrAbort
+	case errors.Is(err, ErrQuit):
+		return ErrQuit
+	case errors.Is(err, ErrSignal):
+		return ErrSignal
+	}
+	return err
+}
+
+// SetVar sets a value used by the shell command templates
+func (w *Wyrm) SetVar(name, value string) {
+	w.vars[name] = value
+}
+
+// GetVar returns a value set with SetVar or entered in a shell command template
+func (w *Wyrm) GetVar(name string) string {
+	return w.vars[name]
+}
+
+// parseShell parses a shell command template
+func parseShell(line string) (*template.Template, error) {
+	return template.New("shell").Funcs(template.FuncMap{
+		"input": func(string) (string, error) { return "", nil },
+		"var":   func(string) string { return "" },
+	}).Parse(line)
+}
+
+// runShell runs line with shell -c, with output to the terminal.
+// On the standard terminal the command is attached to it, in the mode from
+// before Run, so interactive programs like less and vim work. The mode is
+// made raw again from the saved mode afterwards, whatever mode the command
+// left the terminal in.
+//
+// Other commands are killed when ctx is cancelled. Attached commands are not,
+// they get the signal keys from the terminal themselves and may handle them,
+// like less does with Ctrl-C.
+func (w *Wyrm) runShell(ctx context.Context, shell, line string) error {
+	cmd := exec.CommandContext(ctx, shell, "-c", line)
+	cmd.Stdout = w.term
+	cmd.Stderr = w.term
+
+	if t, ok := w.term.(*stdTerminal); ok {
+		cmd = exec.Command(shell, "-c", line)
+		cmd.Stdin = t.in
+		cmd.Stdout = t.out
+		cmd.Stderr = os.Stderr
+
+		t.suspend()
+		defer t.resume()
+	}
+
+	return cmd.Run()
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,194 @@
+//go:build unix
+
+package wyrm
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// blockingTerminal is a Terminal reading the keys, then blocking until
+// released, reporting when a read is pending, then reading the keys after.
+// The output is sent to written, if set.
+type blockingTerminal struct {
+	keys    string
+	after   string
+	pending chan struct{}
+	release chan struct{}
+	written chan string
+}
+
+func newBlockingTerminal(keys string) *blockingTerminal {
+	return &blockingTerminal{
+		keys:    keys,
+		pending: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+}
+
+func (t *blockingTerminal) Read(p []byte) (int, error) {
+	if t.keys != "" {
+		n := copy(p, t.keys)
+		t.keys = t.keys[n:]
+		return n, nil
+	}
+
+	if t.release != nil {
+		select {
+		case t.pending <- struct{}{}:
+		default:
+		}
+		<-t.release
+		t.release = nil
+	}
+	if t.after != "" {
+		n := copy(p, t.after)
+		t.after = t.after[n:]
+		return n, nil
+	}
+	return 0, io.EOF
+}
+
+func (t *blockingTerminal) Write(p []byte) (int, error) {
+	if t.written != nil {
+		t.written <- string(p)
+	}
+	return len(p), nil
+}
+
+func (t *blockingTerminal) IsTerminal() bool { return false }
+func (t *blockingTerminal) MakeRaw() error   { return nil }
+func (t *blockingTerminal) Restore() error   { return nil }
+
+// runSignaled runs input as the function of a command, sending sig when
+// the terminal read of input is pending. Returns the errors of input and Run.
+func runSignaled(t *testing.T, input func() error, sig syscall.Signal) (error, error) {
+	// Keep the test alive if the signal isn't handled by Run
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	var inputErr error
+	returned := make(chan struct{})
+	root := &Command{
+		Title: "root",
+		Commands: map[Key]*Command{
+			'i': {Title: "input", Function: func() error {
+				inputErr = input()
+				close(returned)
+				return inputErr
+			}},
+		},
+	}
+
+	bt := newBlockingTerminal("i")
+	go func() {
+		<-bt.pending
+		syscall.Kill(os.Getpid(), sig)
+		select {
+		case <-returned:
+		case <-time.After(5 * time.Second):
+			t.Errorf("input still waiting after %s", sig)
+		}
+		close(bt.release)
+	}()
+
+	err := New(root, WithTerminal(bt)).Run()
+	return inputErr, err
+}
+
+func TestInputSignal(t *testing.T) {
+	items := []string{"one", "two"}
+	inputs := map[string]func() error{
+		"InputRune":        func() error { _, err := InputRune("> "); return err },
+		"InputSelect":      func() error { _, _, err := InputSelect("> ", items); return err },
+		"InputIndexed":     func() error { _, err := InputIndexed("> ", items); return err },
+		"InputMultiSelect": func() error { _, err := InputMultiSelect("> ", items, nil); return err },
+		"InputSecret":      func() error { _, err := InputSecret("> "); return err },
+	}
+	cases := []struct {
+		sig      syscall.Signal
+		inputErr error
+		runErr   error
+	}{
+		{syscall.SIGINT, ErrAbort, nil},
+		{syscall.SIGTERM, ErrSignal, ErrSignal},
+	}
+
+	for name, input := range inputs {
+		for _, c := range cases {
+			inputErr, err := runSignaled(t, input, c.sig)
+			if inputErr != c.inputErr {
+				t.Errorf("%s on %s error %q, expected %q", name, c.sig, inputErr, c.inputErr)
+			}
+			if err != c.runErr {
+				t.Errorf("Run with %s on %s error %q, expected %q", name, c.sig, err, c.runErr)
+			}
+		}
+	}
+}
+
+func TestInputTextSignal(t *testing.T) {
+	input := func() error { _, err := InputText("> ", ""); return err }
+	for _, sig := range []syscall.Signal{syscall.SIGINT, syscall.SIGTERM} {
+		inputErr, err := runSignaled(t, input, sig)
+		if inputErr != ErrSignal || err != ErrSignal {
+			t.Errorf("InputText on %s errors %q and %q, expected %q", sig, inputErr, err, ErrSignal)
+		}
+	}
+}
+
+func TestConfirmSignal(t *testing.T) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	calls := 0
+	root := &Command{
+		Title: "root",
+		Commands: map[Key]*Command{
+			'd': {Title: "delete", Confirm: "delete all", Function: func() error { calls++; return nil }},
+		},
+	}
+
+	bt := newBlockingTerminal("d")
+	bt.after = "y"
+	bt.written = make(chan string, 100)
+	go func() {
+		<-bt.pending
+		for len(bt.written) > 0 {
+			<-bt.written
+		}
+		syscall.Kill(os.Getpid(), syscall.SIGINT)
+
+		// Answer y when the confirmation has returned to the command prompt,
+		// or when it still waits
+		timeout := time.After(time.Second)
+		for prompt := false; !prompt; {
+			select {
+			case s := <-bt.written:
+				prompt = strings.HasPrefix(s, "root ")
+			case <-timeout:
+				prompt = true
+			}
+		}
+		close(bt.release)
+
+		for range bt.written {
+		}
+	}()
+
+	err := New(root, WithTerminal(bt)).Run()
+	close(bt.written)
+	if err != nil {
+		t.Fatalf("Run error %q, expected nil", err)
+	}
+	if calls != 0 {
+		t.Errorf("calls = %d, expected SIGINT to decline", calls)
+	}
+}
@@ -0,0 +1,70 @@
+// Package wyrm terminal abstraction
+package wyrm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/chzyer/readline"
+)
+
+// Terminal defines the device keys are read from and output is written to
+type Terminal interface {
+	io.Reader
+	io.Writer
+	IsTerminal() bool // true if connected to an interactive terminal
+	MakeRaw() error   // disable line buffering and echo
+	Restore() error   // restore the mode from before MakeRaw
+}
+
+// Printf formats and writes to the terminal of the running Wyrm
+func Printf(format string, a ...interface{}) (int, error) {
+	return current().Printf(format, a...)
+}
+
+// Printf formats and writes to the terminal of w
+func (w *Wyrm) Printf(format string, a ...interface{}) (int, error) {
+	return fmt.Fprintf(w.term, format, a...)
+}
+
+// Println writes the operands and a newline to the terminal of the running Wyrm
+func Println(a ...interface{}) (int, error) {
+	return current().Println(a...)
+}
+
+// Println writes the operands and a newline to the terminal of w
+func (w *Wyrm) Println(a ...interface{}) (int, error) {
+	return fmt.Fprintln(w.term, a...)
+}
+
+// stdTerminal is a Terminal reading from stdin and writing to stdout
+type stdTerminal struct {
+	in    *os.File
+	out   *os.File
+	m     sync.Mutex // Restore may be called on a signal
+	saved *termState // mode from before MakeRaw
+
+	wakeOnce sync.Once
+	wake     [2]int // pipe ending the read in progress, see cancelRead
+	wakeErr  error
+}
+
+// stdTerm is the terminal of stdin and stdout, there is only one
+var stdTerm = &stdTerminal{in: os.Stdin, out: os.Stdout}
+
+// NewStdTerminal returns the Terminal using stdin and stdout
+func NewStdTerminal() Terminal {
+	return stdTerm
+}
+
+// Write writes to stdout
+func (t *stdTerminal) Write(p []byte) (int, error) {
+	return t.out.Write(p)
+}
+
+// IsTerminal returns true if stdin is a terminal
+func (t *stdTerminal) IsTerminal() bool {
+	return readline.IsTerminal(int(t.in.Fd()))
+}
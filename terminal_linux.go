@@ -0,0 +1,11 @@
+// Package wyrm termios requests for Linux
+package wyrm
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
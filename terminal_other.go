@@ -0,0 +1,32 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+// Package wyrm terminal mode handling where termios is missing
+package wyrm
+
+// termState is the saved terminal mode
+type termState struct{}
+
+// Read reads from stdin
+func (t *stdTerminal) Read(p []byte) (int, error) {
+	return t.in.Read(p)
+}
+
+// MakeRaw does nothing, the line editor handles the terminal
+func (t *stdTerminal) MakeRaw() error {
+	return nil
+}
+
+// Restore does nothing
+func (t *stdTerminal) Restore() error {
+	return nil
+}
+
+// suspend does nothing
+func (t *stdTerminal) suspend() error {
+	return nil
+}
+
+// resume does nothing
+func (t *stdTerminal) resume() error {
+	return nil
+}
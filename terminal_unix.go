@@ -0,0 +1,133 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+// Package wyrm terminal mode handling using termios
+package wyrm
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// termState is the saved terminal mode
+type termState = unix.Termios
+
+// Read reads from stdin, or returns errReadCanceled if cancelRead is
+// called while waiting
+func (t *stdTerminal) Read(p []byte) (int, error) {
+	if t.initWake() != nil {
+		return t.in.Read(p)
+	}
+
+	fds := []unix.PollFd{
+		{Fd: int32(t.in.Fd()), Events: unix.POLLIN},
+		{Fd: int32(t.wake[0]), Events: unix.POLLIN},
+	}
+	for {
+		_, err := unix.Poll(fds, -1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil || fds[0].Revents&unix.POLLNVAL != 0 {
+			// Some systems can't poll terminals
+			return t.in.Read(p)
+		}
+		break
+	}
+
+	if fds[1].Revents != 0 {
+		buf := make([]byte, 16)
+		for n, _ := unix.Read(t.wake[0], buf); n > 0; n, _ = unix.Read(t.wake[0], buf) {
+		}
+		return 0, errReadCanceled
+	}
+	return t.in.Read(p)
+}
+
+// cancelRead makes the read in progress return errReadCanceled, leaving
+// the input for others. A cancel while not reading can make the next read
+// return errReadCanceled.
+func (t *stdTerminal) cancelRead() {
+	if t.initWake() == nil {
+		unix.Write(t.wake[1], []byte{0})
+	}
+}
+
+// initWake creates the pipe used by cancelRead, once
+func (t *stdTerminal) initWake() error {
+	t.wakeOnce.Do(func() {
+		if t.wakeErr = unix.Pipe(t.wake[:]); t.wakeErr == nil {
+			unix.CloseOnExec(t.wake[0])
+			unix.CloseOnExec(t.wake[1])
+			t.wakeErr = unix.SetNonblock(t.wake[0], true)
+		}
+	})
+	return t.wakeErr
+}
+
+// MakeRaw saves the current mode and disables buffering and display.
+// The mode is saved once, until Restore, so calling it again doesn't
+// replace the original mode with whatever mode the terminal was left in.
+func (t *stdTerminal) MakeRaw() error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.saved == nil {
+		tio, err := unix.IoctlGetTermios(int(t.in.Fd()), ioctlGetTermios)
+		if err != nil {
+			return err
+		}
+		t.saved = tio
+	}
+
+	return t.setRaw()
+}
+
+// Restore restores the mode saved by MakeRaw
+func (t *stdTerminal) Restore() error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.saved == nil {
+		return nil
+	}
+
+	err := unix.IoctlSetTermios(int(t.in.Fd()), ioctlSetTermios, t.saved)
+	t.saved = nil
+	return err
+}
+
+// suspend sets the mode saved by MakeRaw while keeping it for resume
+func (t *stdTerminal) suspend() error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.saved == nil {
+		return nil
+	}
+
+	return unix.IoctlSetTermios(int(t.in.Fd()), ioctlSetTermios, t.saved)
+}
+
+// resume disables buffering and display again after suspend, unless the
+// terminal was restored meanwhile
+func (t *stdTerminal) resume() error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.saved == nil {
+		return nil
+	}
+
+	return t.setRaw()
+}
+
+// setRaw sets the saved mode without buffering and display
+func (t *stdTerminal) setRaw() error {
+	tio := *t.saved
+
+	// Like stty cbreak min 1 -echo, signal keys still work
+	tio.Lflag &^= unix.ICANON | unix.ECHO
+	tio.Cc[unix.VMIN] = 1
+	tio.Cc[unix.VTIME] = 0
+
+	return unix.IoctlSetTermios(int(t.in.Fd()), ioctlSetTermios, &tio)
+}
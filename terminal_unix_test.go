@@ -0,0 +1,31 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package wyrm
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStopRead(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	kr := newKeyReader(&stdTerminal{in: r, out: os.Stdout})
+	kr.startRead()
+	kr.stopRead()
+
+	// Nothing is left reading the input
+	w.Write([]byte("x"))
+	b := make([]byte, 1)
+	if n, err := r.Read(b); n != 1 || b[0] != 'x' || err != nil {
+		t.Errorf("Read = (%d, %v) %q, expected 1 byte x", n, err, b)
+	}
+	if len(kr.unread) != 0 {
+		t.Errorf("unread = %q, expected nothing", kr.unread)
+	}
+}
@@ -2,17 +2,70 @@
 package wyrm
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
-	"runtime"
+	"io"
+	"os"
+	"os/signal"
 	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/chzyer/readline"
 )
 
+// DefaultChordTimeout is the time to wait for the next key of a chord
+const DefaultChordTimeout = time.Second
+
+// maxCount is the max numeric prefix
+const maxCount = 1 << 20
+
 // Wyrm is the quick command handler
 type Wyrm struct {
-	rootCommand *Command // the root of all evil
-	state       state    // the current state
-	prompter    Prompter // prompt printer interface
+	rootCommand  *Command          // the root of all evil
+	state        state             // the current state
+	prompter     Prompter          // prompt printer interface
+	term         Terminal          // input and output device
+	stop         chan struct{}     // signaled by Stop
+	signaled     chan struct{}     // closed when Run ends on a signal
+	chordTimeout time.Duration     // time to wait for the next key of a chord
+	vars         map[string]string // values for shell command templates
+	shell        string            // shell used by the ! command
+	history      historyConfig     // input history settings
+
+	// Input state, used by the input functions of the Wyrm
+	keys      *keyReader                    // keys read from term
+	lines     map[string]*readline.Instance // line editor per history kind
+	running   running                       // the running context aware command
+	interrupt interrupt                     // the input function waiting for SIGINT
+}
+
+// Option configures a Wyrm created with New
+type Option func(*Wyrm)
+
+// WithTerminal makes Wyrm use t instead of stdin and stdout, e.g. a
+// network connection
+func WithTerminal(t Terminal) Option {
+	return func(w *Wyrm) {
+		w.term = t
+	}
+}
+
+// WithChordTimeout sets the time to wait for the next key of a chord,
+// before falling back to the single key command
+func WithChordTimeout(d time.Duration) Option {
+	return func(w *Wyrm) {
+		w.chordTimeout = d
+	}
+}
+
+// WithShell sets the shell used by the ! command, instead of $SHELL
+func WithShell(shell string) Option {
+	return func(w *Wyrm) {
+		w.shell = shell
+	}
 }
 
 // Command has a description, function and a map of sub commands.
@@ -21,17 +74,42 @@ type Command struct {
 	Title       string
 	Description string
 	Sort        int
-	Commands    map[rune]*Command
+	Repeat      bool // call Function count times, see GetCurrentCount
+	Commands    map[Key]*Command
+	Chords      map[string]*Command // commands for key sequences, e.g. "gg"
 	Parent      *Command
 	Function    func() error
 	Pre         func() error
 	Post        func() error
+	Shell       string // shell command line run if no function is set, see ShellCmd
+	Confirm     string // question answered with y before running the function
+
+	// Context aware alternatives, used instead of the above if set
+	FunctionCtx func(*Context) error
+	PreCtx      func(*Context) error
+	PostCtx     func(*Context) error
+}
+
+// hasFunction returns true if the command has any kind of function
+func (c *Command) hasFunction() bool {
+	return c.Function != nil || c.FunctionCtx != nil || c.Shell != ""
+}
+
+// functionCtx returns FunctionCtx, or a ShellCmd function if only Shell is set
+func (c *Command) functionCtx() func(*Context) error {
+	if c.Function == nil && c.FunctionCtx == nil && c.Shell != "" {
+		return ShellCmd(c.Shell)
+	}
+	return c.FunctionCtx
 }
 
 // state struct holds the internal current state of Wyrm
 type state struct {
-	key rune     // pressed key
-	cmd *Command // current command
+	key   Key      // pressed key
+	cmd   *Command // current command
+	path  []rune   // keys from the root to the current command
+	steps []int    // number of keys in path for each command, chords have more
+	count int      // numeric prefix typed before the key
 }
 
 type stateByOrder []state
@@ -53,19 +131,74 @@ func (a stateByOrder) Less(i, j int) bool {
 }
 
 // New creates a new wyrm
-func New(rootCommand *Command) *Wyrm {
+func New(rootCommand *Command, opts ...Option) *Wyrm {
 	w := Wyrm{
-		rootCommand,
-		state{
+		rootCommand: rootCommand,
+		state: state{
 			key: rune(' '),
 			cmd: rootCommand,
 		},
-		nil,
+		term:         NewStdTerminal(),
+		stop:         make(chan struct{}, 1),
+		chordTimeout: DefaultChordTimeout,
+		vars:         map[string]string{},
+		shell:        os.Getenv("SHELL"),
+		history:      historyConfig{limit: DefaultHistoryLimit},
+		lines:        map[string]*readline.Instance{},
+	}
+	if w.shell == "" {
+		w.shell = "/bin/sh"
+	}
+
+	for _, opt := range opts {
+		opt(&w)
+	}
+	w.keys = newKeyReader(w.term)
+	if w.term == stdTerm {
+		w.keys = stdKeys
 	}
 
 	return &w
 }
 
+// runs holds the running Wyrms, the last started last
+var runs struct {
+	sync.Mutex
+	ws []*Wyrm
+}
+
+// idle is the Wyrm used by the package input functions when none is running
+var idle = New(nil)
+
+// current returns the Wyrm used by the package input functions, the last
+// started of the running ones
+func current() *Wyrm {
+	runs.Lock()
+	defer runs.Unlock()
+
+	if n := len(runs.ws); n > 0 {
+		return runs.ws[n-1]
+	}
+	return idle
+}
+
+// setRunning adds w to the running Wyrms, or removes it if not running
+func setRunning(w *Wyrm, running bool) {
+	runs.Lock()
+	defer runs.Unlock()
+
+	if running {
+		runs.ws = append(runs.ws, w)
+		return
+	}
+	for i, r := range runs.ws {
+		if r == w {
+			runs.ws = append(runs.ws[:i], runs.ws[i+1:]...)
+			return
+		}
+	}
+}
+
 // SetPrompter sets the Prompter to use instead of the default
 func (w *Wyrm) SetPrompter(p Prompter) {
 	w.prompter = p
@@ -81,13 +214,19 @@ func (w *Wyrm) GetCurrentKey() rune {
 	return w.state.key
 }
 
+// GetCurrentCount returns the numeric prefix, e.g. 3 for "3j".
+// It is 0 if no prefix was typed.
+func (w *Wyrm) GetCurrentCount() int {
+	return w.state.count
+}
+
 // GetCurrentKeyStrings returns the keys, no special keys, of the Command as stings in alphabetically order
 func (w *Wyrm) GetCurrentKeyStrings() []string {
 	keys := []string{}
 
 	states := []state{}
 	for r, c := range w.state.cmd.Commands {
-		states = append(states, state{r, c})
+		states = append(states, state{key: r, cmd: c})
 	}
 
 	sort.Sort(stateByOrder(states))
@@ -102,74 +241,165 @@ func (w *Wyrm) GetCurrentKeyStrings() []string {
 	return keys
 }
 
-// Run starts the command line interface
-func (w *Wyrm) Run() {
+// Run starts the command line interface.
+// The package input functions, like InputText, use the terminal of the
+// Wyrm while it is running. When several Wyrms run at once, e.g. one per
+// network connection, commands should use the input methods of their
+// Wyrm instead, like ctx.Wyrm.InputText, and their own command trees, as
+// Run sets the Parent of the commands.
+// Run returns nil when the user quits, a command returns ErrQuit,
+// Stop is called or the terminal input ends.
+// On SIGINT, outside input functions and context aware commands, or
+// SIGTERM the terminal is restored, a waiting input function returns
+// ErrSignal and Run returns ErrSignal.
+func (w *Wyrm) Run() error {
+	return w.RunContext(context.Background())
+}
+
+// RunContext is like Run but also returns when ctx is done
+func (w *Wyrm) RunContext(ctx context.Context) error {
+
+	// Make the package input functions use this Wyrm
+	setRunning(w, true)
+	defer setRunning(w, false)
+	defer w.closeLineEditors()
 
-	// Disable buffering and set no display
-	f := "-F"
-	if runtime.GOOS == "darwin" {
-		// Ugly hack because Macos (Darwin) needs -f iso -F
-		f = "-f"
+	// Leave the terminal alone after Run, keeping what was read for the
+	// next reader
+	defer w.keys.stopRead()
+
+	// Forget Stop called while not running
+	select {
+	case <-w.stop:
+	default:
 	}
+	w.signaled = make(chan struct{})
+
+	// Disable buffering and set no display, restored also on panic
+	w.term.MakeRaw()
+	defer w.term.Restore()
 
-	exec.Command("stty", f, "/dev/tty", "cbreak", "min", "1").Run()
-	exec.Command("stty", f, "/dev/tty", "-echo").Run()
+	done := make(chan struct{})
+	defer close(done)
+	go w.handleSignals(done)
 
 	// Loop until quit
+	counting := false
 	for {
+		// Keep the count only while it is typed
+		if !counting {
+			w.state.count = 0
+		}
+		counting = false
+
 		// Prompt
-		input, err := InputRune(w.CommandPrompt())
-		if err == ErrAbort {
-			w.state.cmd = w.state.cmd.Parent
-			if w.state.cmd == nil {
-				w.state.cmd = w.rootCommand
-			}
+		input, err := w.readKey(ctx, w.CommandPrompt(), 0)
+		var chord []Key
+		if err == nil {
+			chord, err = w.readChord(ctx, input)
+		}
+		switch {
+		case err == ErrQuit, err == ErrDone:
+			return nil
+		case err == ErrAbort:
+			w.toParent()
 			continue
+		case err != nil:
+			return err
 		}
 
-		// Get sub command of current command
+		// Digits not bound to commands are a count prefix
+		if w.isCountDigit(input) && chord == nil {
+			w.state.count = w.state.count*10 + int(input-'0')
+			counting = true
+			continue
+		}
+
+		// Get sub command, or chord, of current command
 		cmd, ok := w.state.cmd.Commands[input]
+		keys := []Key{input}
+		if chord != nil {
+			cmd, ok = w.state.cmd.Chords[string(chord)], true
+			keys = chord
+		}
 		if ok {
-			w.state.key = input // remember key
+			w.state.key = keys[len(keys)-1] // remember key
 
 			// Switch to new command
 			cmd.Parent = w.state.cmd
 			w.state.cmd = cmd
+			w.state.path = append(w.state.path, keys...)
+			w.state.steps = append(w.state.steps, len(keys))
 
 			// Execute Pre if present
-			if w.state.cmd.Pre != nil {
-				if err := w.state.cmd.Pre(); err != nil {
-					fmt.Printf("Error: %s\n", err)
-					w.state.cmd = w.rootCommand
+			if cmd.Pre != nil || cmd.PreCtx != nil {
+				if err := w.call(ctx, cmd.Pre, cmd.PreCtx); err != nil {
+					if err == ErrQuit {
+						return nil
+					}
+					if err == ErrSignal {
+						return err
+					}
+					fmt.Fprintf(w.term, "Error: %s\n", err)
+					w.toRoot()
+					continue
+				}
+			}
+
+			// Ask before executing function, if requested. Anything but a
+			// yes, also a signal, declines.
+			if cmd.hasFunction() && cmd.Confirm != "" {
+				ok, err := w.InputConfirm(w.RunePrompt(cmd.Confirm+" "+YesNo(false)), false)
+				switch {
+				case err == ErrDone:
+					return nil
+				case err == ErrSignal:
+					return err
+				case err == nil && !ok, err == ErrAbort:
+					w.toParent()
+					continue
+				case err != nil:
+					fmt.Fprintf(w.term, "Error: %s\n", err)
+					w.toRoot()
 					continue
 				}
 			}
 
 			// Execute function if present
-			if w.state.cmd.Function != nil {
-				err := w.state.cmd.Function()
+			if cmd.hasFunction() {
+				err := w.call(ctx, cmd.Function, cmd.functionCtx())
+				for i := 1; err == nil && cmd.Repeat && i < w.state.count; i++ {
+					err = w.call(ctx, cmd.Function, cmd.functionCtx())
+				}
 				switch {
+				case err == ErrQuit:
+					return nil
+				case err == ErrSignal:
+					return err
 				case err == ErrAbort:
-					w.state.cmd = w.state.cmd.Parent
-					if w.state.cmd == nil {
-						w.state.cmd = w.rootCommand
-					}
+					w.toParent()
 					continue
 				case err == nil:
-					if w.state.cmd.Post != nil {
-						if err := w.state.cmd.Post(); err != nil {
-							fmt.Printf("Error: %s\n", err)
-							w.state.cmd = w.rootCommand
+					if cmd.Post != nil || cmd.PostCtx != nil {
+						if err := w.call(ctx, cmd.Post, cmd.PostCtx); err != nil {
+							if err == ErrQuit {
+								return nil
+							}
+							if err == ErrSignal {
+								return err
+							}
+							fmt.Fprintf(w.term, "Error: %s\n", err)
+							w.toRoot()
 							continue
 						}
 					}
 				default:
-					fmt.Printf("Error: %s\n", err)
+					fmt.Fprintf(w.term, "Error: %s\n", err)
 				}
 
 				// Return to root command, if no sub commands
-				if len(w.state.cmd.Commands) < 1 {
-					w.state.cmd = w.rootCommand
+				if len(cmd.Commands) < 1 {
+					w.toRoot()
 					continue
 				}
 			}
@@ -180,18 +410,238 @@ func (w *Wyrm) Run() {
 
 		// Check global commands (can be override above)
 		if cmd, ok = w.getGlobalCommands()[input]; ok {
-			if cmd.Function == nil {
-				fmt.Println("No function defined")
+			if !cmd.hasFunction() {
+				fmt.Fprintln(w.term, "No function defined")
 				continue
 			}
 
-			err := cmd.Function()
+			err := w.call(ctx, cmd.Function, cmd.functionCtx())
+			if err == ErrQuit {
+				return nil
+			}
+			if err == ErrSignal {
+				return err
+			}
 			if err != nil {
-				fmt.Printf("Error: %s\n", err)
+				fmt.Fprintf(w.term, "Error: %s\n", err)
 			}
 			continue
 		}
 
-		fmt.Printf("Unknown command %s\n", string(input))
+		fmt.Fprintf(w.term, "Unknown command %s\n", KeyName(input))
 	}
 }
+
+// isCountDigit returns true if k is a digit that is part of a count prefix.
+// Zero can't start a count and bound digits are commands.
+func (w *Wyrm) isCountDigit(k Key) bool {
+	if k < '0' || k > '9' || k == '0' && w.state.count == 0 || w.state.count > maxCount/10 {
+		return false
+	}
+
+	_, ok := w.state.cmd.Commands[k]
+	return !ok
+}
+
+// toParent makes the parent of the current command current
+func (w *Wyrm) toParent() {
+	w.state.cmd = w.state.cmd.Parent
+	if w.state.cmd == nil || len(w.state.steps) < 2 {
+		w.toRoot()
+		return
+	}
+	last := len(w.state.steps) - 1
+	w.state.path = w.state.path[:len(w.state.path)-w.state.steps[last]]
+	w.state.steps = w.state.steps[:last]
+}
+
+// toRoot makes the root command current
+func (w *Wyrm) toRoot() {
+	w.state.cmd = w.rootCommand
+	w.state.path = nil
+	w.state.steps = nil
+}
+
+// Stop makes Run return, also while waiting for a key.
+// Has no effect if Run isn't running.
+func (w *Wyrm) Stop() {
+	select {
+	case w.stop <- struct{}{}:
+	default:
+	}
+}
+
+// handleSignals interrupts a waiting input function, or cancels the running
+// command, on SIGINT.
+// Otherwise the terminal is restored and Run, and any waiting input
+// function, is signaled to return.
+// Signals after that have their default behavior.
+func (w *Wyrm) handleSignals(done <-chan struct{}) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case sig := <-sigs:
+			if sig == os.Interrupt && (w.interruptInput() || w.cancelCommand()) {
+				continue
+			}
+			w.term.Restore()
+			close(w.signaled)
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
+// readKey prints the prompt and reads a key.
+// Returns errTimeout if timeout, unless 0, passes before a key is pressed,
+// ErrQuit if Stop is called, ErrSignal on a signal
+// and the context error if ctx is done.
+// An interrupted terminal read is used by the next read.
+func (w *Wyrm) readKey(ctx context.Context, p string, timeout time.Duration) (Key, error) {
+	fmt.Fprint(w.term, p)
+
+	k, err := w.waitKey(ctx, timeout, w.stop, nil)
+	switch {
+	case err == io.EOF, err == io.ErrUnexpectedEOF:
+		return 0, ErrDone
+	case err == errTimeout:
+		fmt.Fprintln(w.term, "")
+		return 0, err
+	case err != nil:
+		return 0, err
+	}
+
+	fmt.Fprintln(w.term, "")
+	if k == RuneEsc {
+		return k, ErrAbort
+	}
+	return k, nil
+}
+
+// waitKey reads a key, a UTF-8 encoded rune or an escape sequence, from
+// the terminal. Esc followed by another key is that key with ModAlt, a lone
+// Esc is RuneEsc if nothing follows within escTimeout. Invalid encodings
+// and unknown sequences are returned as utf8.RuneError.
+// Returns errTimeout if timeout, unless 0, passes before a key is pressed,
+// ErrQuit if stop is signaled, ErrAbort if intr is signaled, ErrSignal if
+// Run ends on a signal and the context error if ctx is done.
+func (w *Wyrm) waitKey(ctx context.Context, timeout time.Duration, stop, intr <-chan struct{}) (Key, error) {
+	tctx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		tctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for {
+		if k, ok := w.keys.takeKey(); ok {
+			return k, nil
+		}
+
+		// Wait a little for the rest of a sequence after Esc, scripts and
+		// other non-interactive input send it in one read
+		fctx, cancel := tctx, context.CancelFunc(func() {})
+		if w.keys.escPending() {
+			if !w.term.IsTerminal() {
+				w.keys.escDone = true
+				continue
+			}
+			fctx, cancel = context.WithTimeout(tctx, escTimeout)
+		}
+
+		err := w.fill(fctx, stop, intr)
+		cancel()
+		switch {
+		case err == nil:
+			continue
+		case ctx.Err() != nil:
+			return 0, ctx.Err()
+		case tctx.Err() != nil:
+			return 0, errTimeout
+		case fctx.Err() != nil:
+			w.keys.escDone = true
+			continue
+		}
+		return 0, err
+	}
+}
+
+// fill waits for the terminal read in progress, or starts one, and adds
+// the read bytes to the unread ones.
+// Returns the errors of waitKey, except for the timeout, if the wait ends
+// before the read.
+func (w *Wyrm) fill(ctx context.Context, stop, intr <-chan struct{}) error {
+	select {
+	case c := <-w.keys.startRead():
+		return w.keys.addRead(c)
+	case <-stop:
+		return ErrQuit
+	case <-intr:
+		return ErrAbort
+	case <-w.signaled:
+		return ErrSignal
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hasSignaled returns true if Run has ended on a signal
+func (w *Wyrm) hasSignaled() bool {
+	select {
+	case <-w.signaled:
+		return true
+	default:
+		return false
+	}
+}
+
+// readChord reads the keys following k as long as they can be part of a
+// chord of the current command, or until the chord timeout passes.
+// Returns the keys of the longest matching chord, or nil if none matched.
+// Keys read but not part of the chord are unread, if no chord matched
+// k is used as a single key.
+func (w *Wyrm) readChord(ctx context.Context, k Key) ([]Key, error) {
+	chords := w.state.cmd.Chords
+	seq := []Key{k}
+	n := 0 // length of the longest match
+	if _, ok := chords[string(seq)]; ok {
+		n = 1
+	}
+
+	for hasLongerChord(chords, string(seq)) {
+		next, err := w.readKey(ctx, w.CommandPrompt()+string(seq), w.chordTimeout)
+		if err == errTimeout {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		seq = append(seq, next)
+		if _, ok := chords[string(seq)]; ok {
+			n = len(seq)
+		}
+	}
+
+	if n == 0 {
+		w.keys.unreadKeys(seq[1:])
+		return nil, nil
+	}
+
+	w.keys.unreadKeys(seq[n:])
+	return seq[:n], nil
+}
+
+// hasLongerChord returns true if any chord is longer than and starts with seq
+func hasLongerChord(chords map[string]*Command, seq string) bool {
+	for c := range chords {
+		if len(c) > len(seq) && strings.HasPrefix(c, seq) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package wyrm
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testTerminal is a non-interactive Terminal reading from a string
+type testTerminal struct {
+	*strings.Reader
+	bytes.Buffer
+}
+
+func (t *testTerminal) Read(p []byte) (int, error) { return t.Reader.Read(p) }
+func (t *testTerminal) IsTerminal() bool           { return false }
+func (t *testTerminal) MakeRaw() error             { return nil }
+func (t *testTerminal) Restore() error             { return nil }
+
+func TestStopBeforeRun(t *testing.T) {
+	calls := 0
+	root := &Command{
+		Title: "root",
+		Commands: map[Key]*Command{
+			'h': {Title: "hello", Function: func() error { calls++; return nil }},
+		},
+	}
+
+	w := New(root, WithTerminal(&testTerminal{Reader: strings.NewReader("h")}))
+	w.Stop()
+	if err := w.Run(); err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, expected Stop before Run to be ignored", calls)
+	}
+}
+
+func TestStopRunAgain(t *testing.T) {
+	calls := 0
+	root := &Command{
+		Title: "root",
+		Commands: map[Key]*Command{
+			'h': {Title: "hello", Function: func() error { calls++; return nil }},
+		},
+	}
+
+	ct := &chunkTerminal{chunks: make(chan string, 1), pending: make(chan struct{}, 1)}
+	w := New(root, WithTerminal(ct))
+	go func() {
+		<-ct.pending
+		w.Stop()
+	}()
+	if err := w.Run(); err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	// The read left by Stop gets the key
+	ct.chunks <- "h"
+	close(ct.chunks)
+	if err := w.Run(); err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, expected the key read after Stop to run the command", calls)
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	var m sync.Mutex
+	inputs := map[*Wyrm]string{}
+	root := func() *Command {
+		return &Command{
+			Title: "root",
+			Commands: map[Key]*Command{
+				'i': {Title: "input", FunctionCtx: func(ctx *Context) error {
+					s, err := ctx.Wyrm.InputText("text> ", "")
+					m.Lock()
+					inputs[ctx.Wyrm] = s
+					m.Unlock()
+					return err
+				}},
+			},
+		}
+	}
+
+	w1 := New(root(), WithTerminal(&testTerminal{Reader: strings.NewReader("ione\n")}))
+	w2 := New(root(), WithTerminal(&testTerminal{Reader: strings.NewReader("itwo\n")}))
+
+	errs := make(chan error, 2)
+	for _, w := range []*Wyrm{w1, w2} {
+		go func(w *Wyrm) {
+			errs <- w.Run()
+		}(w)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Run error %q", err)
+		}
+	}
+
+	if inputs[w1] != "one" || inputs[w2] != "two" {
+		t.Errorf("inputs = %q and %q, expected each Wyrm to read its own terminal", inputs[w1], inputs[w2])
+	}
+}
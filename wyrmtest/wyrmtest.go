@@ -0,0 +1,203 @@
+// Package wyrmtest runs wyrm command trees with scripted input
+package wyrmtest
+
+import (
+	"io"
+	"strings"
+
+	"github.com/callerobertsson/wyrm"
+)
+
+// Kind of a transcript entry
+type Kind int
+
+// Transcript entry kinds
+const (
+	Output Kind = iota // text written by wyrm or the commands
+	Prompt             // text written right before input was read
+	Input              // a step of the script
+	Hook               // a Function, Pre or Post that fired
+)
+
+// Entry is a single transcript entry
+type Entry struct {
+	Kind Kind
+	Text string
+}
+
+// Transcript is the recorded entries of a session, in order
+type Transcript []Entry
+
+// Run runs the command tree with the script as input and returns the transcript.
+// Each step in the script, e.g. "i", "s", "hello\n", is read as one chunk.
+// The session ends when the script is exhausted or a command quits.
+// The error is the one returned by wyrm.Run.
+// The tree is copied, so root is left untouched.
+// Sessions of commands using the package input functions, rather than the
+// methods of ctx.Wyrm, must not run in parallel.
+func Run(root *wyrm.Command, script ...string) (Transcript, error) {
+	t := &terminal{script: script}
+
+	cmd := instrument(root, t.hook, map[*wyrm.Command]*wyrm.Command{})
+	err := wyrm.New(cmd, wyrm.WithTerminal(t)).Run()
+
+	return t.entries, err
+}
+
+// Hooks returns the fired hooks, e.g. "Pre hello", in order
+func (t Transcript) Hooks() []string {
+	return t.texts(Hook)
+}
+
+// Prompts returns the prompts in order
+func (t Transcript) Prompts() []string {
+	return t.texts(Prompt)
+}
+
+// Output returns all output, except prompts, as one string
+func (t Transcript) Output() string {
+	return strings.Join(t.texts(Output), "")
+}
+
+// texts returns the texts of all entries of kind k
+func (t Transcript) texts(k Kind) []string {
+	ts := []string{}
+	for _, e := range t {
+		if e.Kind == k {
+			ts = append(ts, e.Text)
+		}
+	}
+	return ts
+}
+
+// instrument returns a copy of the command tree with hooks reported to rec
+func instrument(c *wyrm.Command, rec func(string), seen map[*wyrm.Command]*wyrm.Command) *wyrm.Command {
+	if cc, ok := seen[c]; ok {
+		return cc
+	}
+
+	cc := *c
+	cc.Parent = nil
+	seen[c] = &cc
+
+	if c.Function == nil && c.FunctionCtx == nil && c.Shell != "" {
+		cc.FunctionCtx = wyrm.ShellCmd(c.Shell)
+	}
+
+	cc.Function = wrap("Function "+c.Title, c.Function, rec)
+	cc.Pre = wrap("Pre "+c.Title, c.Pre, rec)
+	cc.Post = wrap("Post "+c.Title, c.Post, rec)
+	cc.FunctionCtx = wrapCtx("Function "+c.Title, cc.FunctionCtx, rec)
+	cc.PreCtx = wrapCtx("Pre "+c.Title, c.PreCtx, rec)
+	cc.PostCtx = wrapCtx("Post "+c.Title, c.PostCtx, rec)
+
+	if c.Commands != nil {
+		cc.Commands = map[wyrm.Key]*wyrm.Command{}
+		for k, sub := range c.Commands {
+			cc.Commands[k] = instrument(sub, rec, seen)
+		}
+	}
+
+	if c.Chords != nil {
+		cc.Chords = map[string]*wyrm.Command{}
+		for k, sub := range c.Chords {
+			cc.Chords[k] = instrument(sub, rec, seen)
+		}
+	}
+
+	return &cc
+}
+
+// wrap returns f reporting name to rec when called, or nil if f is nil
+func wrap(name string, f func() error, rec func(string)) func() error {
+	if f == nil {
+		return nil
+	}
+
+	return func() error {
+		rec(name)
+		return f()
+	}
+}
+
+// wrapCtx is wrap for context aware functions
+func wrapCtx(name string, f func(*wyrm.Context) error, rec func(string)) func(*wyrm.Context) error {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx *wyrm.Context) error {
+		rec(name)
+		return f(ctx)
+	}
+}
+
+// terminal is a wyrm.Terminal reading from a script and recording a transcript
+type terminal struct {
+	script  []string
+	step    string // rest of the current step
+	entries Transcript
+}
+
+// Read reads from the current step, or starts the next one
+func (t *terminal) Read(p []byte) (int, error) {
+	if t.step == "" {
+		if len(t.script) == 0 {
+			return 0, io.EOF
+		}
+		t.step, t.script = t.script[0], t.script[1:]
+		t.splitPrompt()
+		t.entries = append(t.entries, Entry{Input, t.step})
+	}
+
+	n := copy(p, t.step)
+	t.step = t.step[n:]
+	return n, nil
+}
+
+// Write records output
+func (t *terminal) Write(p []byte) (int, error) {
+	if n := len(t.entries); n > 0 && t.entries[n-1].Kind == Output {
+		t.entries[n-1].Text += string(p)
+		return len(p), nil
+	}
+
+	t.entries = append(t.entries, Entry{Output, string(p)})
+	return len(p), nil
+}
+
+// IsTerminal returns false, scripts are never interactive
+func (t *terminal) IsTerminal() bool { return false }
+
+// MakeRaw does nothing
+func (t *terminal) MakeRaw() error { return nil }
+
+// Restore does nothing
+func (t *terminal) Restore() error { return nil }
+
+// hook records a fired hook
+func (t *terminal) hook(name string) {
+	t.entries = append(t.entries, Entry{Hook, name})
+}
+
+// splitPrompt turns the last line of pending output into a prompt
+func (t *terminal) splitPrompt() {
+	n := len(t.entries)
+	if n == 0 || t.entries[n-1].Kind != Output {
+		return
+	}
+
+	text := t.entries[n-1].Text
+	i := strings.LastIndex(text, "\n") + 1
+	if i == len(text) {
+		return
+	}
+
+	if i == 0 {
+		t.entries[n-1].Kind = Prompt
+		return
+	}
+
+	t.entries[n-1].Text = text[:i]
+	t.entries = append(t.entries, Entry{Prompt, text[i:]})
+}
@@ -0,0 +1,646 @@
+package wyrmtest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/callerobertsson/wyrm"
+)
+
+func testCommands(input *string) *wyrm.Command {
+	return &wyrm.Command{
+		Title: "root",
+		Commands: map[rune]*wyrm.Command{
+			'i': {
+				Title: "input",
+				Pre:   func() error { return nil },
+				Commands: map[rune]*wyrm.Command{
+					's': {
+						Title: "string",
+						Function: func() error {
+							s, err := wyrm.InputText("text> ", "")
+							*input = s
+							return err
+						},
+						Post: func() error { wyrm.Println("got it"); return nil },
+					},
+				},
+			},
+			'h': {
+				Title:    "hello",
+				Function: func() error { wyrm.Println("hello world!"); return nil },
+			},
+		},
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	var input string
+	tr, err := Run(testCommands(&input), "i", "s", "hello\n", "h")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"Pre input", "Function string", "Post string", "Function hello"}
+	if !reflect.DeepEqual(tr.Hooks(), exp) {
+		t.Errorf("Hooks = %q, expected %q", tr.Hooks(), exp)
+	}
+	if input != "hello" {
+		t.Errorf("input = %q, expected %q", input, "hello")
+	}
+}
+
+func TestRunTranscript(t *testing.T) {
+	var input string
+	tr, err := Run(testCommands(&input), "i", "s", "hello\n", "x")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	prompts := tr.Prompts()
+	if len(prompts) != 4 || prompts[2] != "text> " {
+		t.Errorf("Prompts = %q, expected 4 with \"text> \" third", prompts)
+	}
+
+	out := tr.Output()
+	for _, s := range []string{"got it", "Unknown command x"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Output = %q, expected it to contain %q", out, s)
+		}
+	}
+}
+
+func TestRunKeepsRoot(t *testing.T) {
+	var input string
+	root := testCommands(&input)
+	Run(root, "i", "s", "text\n")
+
+	if root.Commands['i'].Parent != nil {
+		t.Errorf("Run changed the Parent of the original tree")
+	}
+}
+
+func TestRunQuit(t *testing.T) {
+	var input string
+	tr, err := Run(testCommands(&input), "q", "h")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	if len(tr.Hooks()) != 0 {
+		t.Errorf("Hooks = %q, expected none after quit", tr.Hooks())
+	}
+	if !strings.Contains(tr.Output(), "bye!") {
+		t.Errorf("Output = %q, expected it to contain %q", tr.Output(), "bye!")
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	var got *wyrm.Context
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[rune]*wyrm.Command{
+			'a': {
+				Title: "a",
+				Commands: map[rune]*wyrm.Command{
+					'b': {
+						Title: "b",
+						FunctionCtx: func(ctx *wyrm.Context) error {
+							got = ctx
+							_, err := wyrm.InputRune("key> ")
+							return err
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tr, err := Run(root, "a", "b", "\x1b")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"Function b"}
+	if !reflect.DeepEqual(tr.Hooks(), exp) {
+		t.Errorf("Hooks = %q, expected %q", tr.Hooks(), exp)
+	}
+	if got == nil {
+		t.Fatalf("FunctionCtx not called")
+	}
+	if got.Key != 'b' || string(got.Path) != "ab" || got.Command.Title != "b" {
+		t.Errorf("Context = (%q, %q, %q), expected ('b', \"ab\", \"b\")", got.Key, string(got.Path), got.Command.Title)
+	}
+	if got.Err() == nil {
+		t.Errorf("Context not cancelled by Esc")
+	}
+}
+
+func TestRunUnicodeKey(t *testing.T) {
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[rune]*wyrm.Command{
+			'ö': {Title: "öl", Function: func() error { return nil }},
+		},
+	}
+
+	tr, err := Run(root, "ö")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"Function öl"}
+	if !reflect.DeepEqual(tr.Hooks(), exp) {
+		t.Errorf("Hooks = %q, expected %q", tr.Hooks(), exp)
+	}
+}
+
+func TestRunNamedKey(t *testing.T) {
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[rune]*wyrm.Command{
+			wyrm.RuneUp: {Title: "up", Function: func() error { return nil }},
+		},
+	}
+
+	tr, err := Run(root, "\x1b[A", "\x1b[B")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"Function up"}
+	if !reflect.DeepEqual(tr.Hooks(), exp) {
+		t.Errorf("Hooks = %q, expected %q", tr.Hooks(), exp)
+	}
+	if !strings.Contains(tr.Output(), "Unknown command down") {
+		t.Errorf("Output = %q, expected unknown command down", tr.Output())
+	}
+}
+
+func TestRunChords(t *testing.T) {
+	cmd := func(title string) *wyrm.Command {
+		return &wyrm.Command{Title: title, Function: func() error { return nil }}
+	}
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'g':           cmd("g"),
+			'x':           cmd("x"),
+			wyrm.Alt('x'): cmd("M-x"),
+			wyrm.RuneUp:   cmd("up"),
+		},
+		Chords: map[string]*wyrm.Command{
+			"gg":  cmd("gg"),
+			"dd":  cmd("dd"),
+			"dgg": cmd("dgg"),
+		},
+	}
+
+	cases := []struct {
+		script []string
+		hooks  []string
+	}{
+		{[]string{"g", "g"}, []string{"Function gg"}},
+		{[]string{"gg"}, []string{"Function gg"}},
+		{[]string{"g", "x"}, []string{"Function g", "Function x"}},
+		{[]string{"g", "\x1bx"}, []string{"Function g", "Function M-x"}},
+		{[]string{"g", "\x1b[A"}, []string{"Function g", "Function up"}},
+		{[]string{"d", "d", "x"}, []string{"Function dd", "Function x"}},
+		{[]string{"d", "g", "g", "g", "g"}, []string{"Function dgg", "Function gg"}},
+	}
+
+	for _, c := range cases {
+		tr, err := Run(root, c.script...)
+		if err != nil {
+			t.Fatalf("Run error %q", err)
+		}
+		if !reflect.DeepEqual(tr.Hooks(), c.hooks) {
+			t.Errorf("Run(%q) hooks = %q, expected %q", c.script, tr.Hooks(), c.hooks)
+		}
+	}
+}
+
+func TestRunCount(t *testing.T) {
+	var counts []int
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'j': {
+				Title:  "next",
+				Repeat: true,
+				Function: func() error {
+					counts = append(counts, -1)
+					return nil
+				},
+			},
+			'c': {
+				Title: "count",
+				FunctionCtx: func(ctx *wyrm.Context) error {
+					counts = append(counts, ctx.Count)
+					return nil
+				},
+			},
+			'1': {Title: "one", Function: func() error { return nil }},
+		},
+	}
+
+	tr, err := Run(root, "3", "j", "c", "2", "0", "c", "0", "j", "1")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []int{-1, -1, -1, 0, 20, -1}
+	if !reflect.DeepEqual(counts, exp) {
+		t.Errorf("counts = %v, expected %v", counts, exp)
+	}
+	if !strings.HasSuffix(tr.Hooks()[len(tr.Hooks())-1], "one") {
+		t.Errorf("Hooks = %q, expected bound digit to be a command", tr.Hooks())
+	}
+	if prompt := tr.Prompts()[5]; !strings.HasSuffix(prompt, "] 20$ ") {
+		t.Errorf("Prompt = %q, expected count 20", prompt)
+	}
+}
+
+func TestRunShell(t *testing.T) {
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'e': {Title: "echo", Shell: `echo hello {{input "name"}}`},
+			'a': {Title: "again", Shell: `echo again {{var "name"}}; echo err >&2`},
+			'f': {Title: "fail", Shell: `exit 3`},
+		},
+	}
+
+	tr, err := Run(root, "e", "it's me\n", "a", "f")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"Function echo", "Function again", "Function fail"}
+	if !reflect.DeepEqual(tr.Hooks(), exp) {
+		t.Errorf("Hooks = %q, expected %q", tr.Hooks(), exp)
+	}
+	for _, s := range []string{"hello it's me\n", "again it's me\n", "err\n", "Error: exit status 3"} {
+		if !strings.Contains(tr.Output(), s) {
+			t.Errorf("Output = %q, expected it to contain %q", tr.Output(), s)
+		}
+	}
+}
+
+func TestRunShellInputAbort(t *testing.T) {
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'e': {Title: "echo", Shell: `echo hello {{input "name"}}`},
+		},
+	}
+
+	tr, err := Run(root, "e", "\n", "e", "\x03")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	if strings.Contains(tr.Output(), "Error") || strings.Contains(tr.Output(), "hello") {
+		t.Errorf("Output = %q, expected the commands aborted quietly", tr.Output())
+	}
+}
+
+func TestRunShellCommand(t *testing.T) {
+	root := &wyrm.Command{Title: "root"}
+
+	tr, err := Run(root, "!", "echo 'a  b' | tr a-z A-Z; exit 2\n", "!", "\n")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	for _, s := range []string{"A  B\n", "[exit status 2]\n"} {
+		if !strings.Contains(tr.Output(), s) {
+			t.Errorf("Output = %q, expected it to contain %q", tr.Output(), s)
+		}
+	}
+	if strings.Contains(tr.Output(), "Error") {
+		t.Errorf("Output = %q, expected no errors", tr.Output())
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	var inputs []string
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			's': {
+				Title: "string",
+				Function: func() error {
+					s, err := wyrm.InputText("text> ", "")
+					inputs = append(inputs, s)
+					return err
+				},
+			},
+		},
+	}
+
+	tr, err := Run(root, "!", "echo one\n", "s", "two\n", "!", "\x1b[A\n", "s", "\x10\n")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"two", "two"}
+	if !reflect.DeepEqual(inputs, exp) {
+		t.Errorf("inputs = %q, expected %q", inputs, exp)
+	}
+	if n := strings.Count(tr.Output(), "one\n"); n != 2 {
+		t.Errorf("Output = %q, expected shell history to run echo one twice", tr.Output())
+	}
+}
+
+func TestRunSelect(t *testing.T) {
+	var selected []string
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			's': {
+				Title: "select",
+				Function: func() error {
+					_, item, err := wyrm.InputSelect("select> ", []string{"apple", "banana", "cherry"})
+					selected = append(selected, item)
+					return err
+				},
+			},
+		},
+	}
+
+	_, err := Run(root, "s", "\n", "s", "an", "\n", "s", "\x1b[B", "\x1b[B", "\n", "s", "\x1bc", "s", "\x1b")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"apple", "banana", "cherry", "cherry", ""}
+	if !reflect.DeepEqual(selected, exp) {
+		t.Errorf("selected = %q, expected %q", selected, exp)
+	}
+}
+
+func TestRunIndexed(t *testing.T) {
+	defer wyrm.SetIndexRunes(wyrm.GetIndexRunes())
+	if err := wyrm.SetIndexRunes([]rune("abc")); err != nil {
+		t.Fatalf("SetIndexRunes error %q", err)
+	}
+
+	var selected []int
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			's': {
+				Title: "select",
+				Function: func() error {
+					i, err := wyrm.InputIndexed("index> ", []string{"one", "two", "three", "four", "five"})
+					selected = append(selected, i)
+					return err
+				},
+			},
+		},
+	}
+
+	tr, err := Run(root, "s", "b", "s", ">", "c", "b", "s", ">", "\x1b[D", "<", "a", "s", "\x1b")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []int{1, 4, 0, -1}
+	if !reflect.DeepEqual(selected, exp) {
+		t.Errorf("selected = %v, expected %v", selected, exp)
+	}
+	for _, s := range []string{"  c: three\n", "  b: five\n", "(page 2/2, < > for more)"} {
+		if !strings.Contains(tr.Output(), s) {
+			t.Errorf("Output = %q, expected it to contain %q", tr.Output(), s)
+		}
+	}
+}
+
+func TestRunMultiSelect(t *testing.T) {
+	var selected [][]int
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			's': {
+				Title: "select",
+				Function: func() error {
+					is, err := wyrm.InputMultiSelect("tags> ", []string{"red", "green", "blue"}, []int{1, 7})
+					selected = append(selected, is)
+					return err
+				},
+			},
+		},
+	}
+
+	tr, err := Run(root, "s", "\n", "s", "a", "b", "c", "\n", "s", "-", "\n", "s", "+", "c", "\n", "s", "a", "\x1b")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := [][]int{{1}, {0, 2}, {}, {0, 1}, nil}
+	if !reflect.DeepEqual(selected, exp) {
+		t.Errorf("selected = %v, expected %v", selected, exp)
+	}
+	for _, s := range []string{"  a: [ ] red\n", "  b: [x] green\n"} {
+		if !strings.Contains(tr.Output(), s) {
+			t.Errorf("Output = %q, expected it to contain %q", tr.Output(), s)
+		}
+	}
+}
+
+func TestRunConfirm(t *testing.T) {
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'd': {
+				Title:    "delete",
+				Confirm:  "delete all",
+				Function: func() error { return nil },
+			},
+		},
+	}
+
+	tr, err := Run(root, "d", "x", "y", "d", "\n", "d", "N", "d", "\x1b", "d", "Y")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"Function delete", "Function delete"}
+	if !reflect.DeepEqual(tr.Hooks(), exp) {
+		t.Errorf("Hooks = %q, expected %q", tr.Hooks(), exp)
+	}
+	if n := strings.Count(strings.Join(tr.Prompts(), "\n"), "delete all [y/N]"); n != 6 {
+		t.Errorf("Prompts = %q, expected 6 confirmations", tr.Prompts())
+	}
+}
+
+func TestRunInputRetry(t *testing.T) {
+	var inputs []int
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'n': {
+				Title: "number",
+				Function: func() error {
+					i, err := wyrm.InputInt("number> ", "", 10)
+					inputs = append(inputs, i)
+					return err
+				},
+			},
+		},
+	}
+
+	tr, err := Run(root, "n", "x\n", "\x7f11\n", "\x7f\x7f7\n")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	if !reflect.DeepEqual(inputs, []int{7}) {
+		t.Errorf("inputs = %v, expected %v", inputs, []int{7})
+	}
+	for _, s := range []string{"Error: not a number\n", "Error: 11 is out of range 0 to 10\n"} {
+		if !strings.Contains(tr.Output(), s) {
+			t.Errorf("Output = %q, expected it to contain %q", tr.Output(), s)
+		}
+	}
+}
+
+func TestRunSecret(t *testing.T) {
+	var secrets []string
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'p': {
+				Title: "password",
+				Function: func() error {
+					b, err := wyrm.InputSecret("password> ")
+					secrets = append(secrets, string(b))
+					return err
+				},
+			},
+		},
+	}
+
+	tr, err := Run(root, "p", "sx\x7fecret\n", "p", "abc\x15ok\n", "p", "abc\x1b", "p", "x\x03")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"secret", "ok", "", ""}
+	if !reflect.DeepEqual(secrets, exp) {
+		t.Errorf("secrets = %q, expected %q", secrets, exp)
+	}
+	if strings.Contains(tr.Output(), "secret") || !strings.Contains(tr.Output(), "*\b \b*") {
+		t.Errorf("Output = %q, expected the secret masked", tr.Output())
+	}
+}
+
+func TestRunMultiline(t *testing.T) {
+	var texts []string
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'm': {
+				Title: "multiline",
+				Function: func() error {
+					s, err := wyrm.InputMultiline("notes>", "first")
+					texts = append(texts, s)
+					return err
+				},
+			},
+		},
+	}
+
+	t.Setenv("VISUAL", `printf 'edited\n' >>`)
+	_, err := Run(root, "m", "one\n", "two\n", ".\n", "m", "\x04", "m", "more\x18")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"first\none\ntwo", "first", "first\nmore\nedited"}
+	if !reflect.DeepEqual(texts, exp) {
+		t.Errorf("texts = %q, expected %q", texts, exp)
+	}
+}
+
+func TestRunLeftoverKeys(t *testing.T) {
+	var input string
+	if _, err := Run(testCommands(&input), "qh"); err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	tr, err := Run(testCommands(&input), "x")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+	if len(tr.Hooks()) != 0 {
+		t.Errorf("Hooks = %q, expected no keys from the previous session", tr.Hooks())
+	}
+}
+
+func TestRunChordAbortPath(t *testing.T) {
+	var paths []string
+	record := func(ctx *wyrm.Context) error {
+		paths = append(paths, string(ctx.Path))
+		return nil
+	}
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'p': {Title: "path", FunctionCtx: record},
+			's': {
+				Title: "sub",
+				Chords: map[string]*wyrm.Command{
+					"gg": {Title: "top", Confirm: "go to top", FunctionCtx: record},
+				},
+				Commands: map[wyrm.Key]*wyrm.Command{
+					'p': {Title: "path", FunctionCtx: record},
+				},
+			},
+		},
+	}
+
+	_, err := Run(root, "s", "g", "g", "n", "p", "p")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	exp := []string{"sp", "p"}
+	if !reflect.DeepEqual(paths, exp) {
+		t.Errorf("paths = %q, expected %q", paths, exp)
+	}
+}
+
+func TestRunMultilineEditorThenKeys(t *testing.T) {
+	var texts []string
+	root := &wyrm.Command{
+		Title: "root",
+		Commands: map[wyrm.Key]*wyrm.Command{
+			'm': {
+				Title: "multiline",
+				Function: func() error {
+					s, err := wyrm.InputMultiline("notes>", "")
+					texts = append(texts, s)
+					return err
+				},
+			},
+			'h': {Title: "hello", Function: func() error { return nil }},
+		},
+	}
+
+	t.Setenv("VISUAL", `printf 'edited\n' >>`)
+	tr, err := Run(root, "m", "more\x18", "h", "h")
+	if err != nil {
+		t.Fatalf("Run error %q", err)
+	}
+
+	if exp := []string{"more\nedited"}; !reflect.DeepEqual(texts, exp) {
+		t.Errorf("texts = %q, expected %q", texts, exp)
+	}
+	exp := []string{"Function multiline", "Function hello", "Function hello"}
+	if !reflect.DeepEqual(tr.Hooks(), exp) {
+		t.Errorf("Hooks = %q, expected %q", tr.Hooks(), exp)
+	}
+}